@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConditionalPermission is a permission with optional attribute conditions
+// that further restrict it, e.g. {"region": "us"}. The conditions are kept out
+// of Permission so that Permission stays comparable.
+type ConditionalPermission struct {
+	Permission
+	Conditions map[string]string
+}
+
+// ConditionalRequirement is a permission requirement with optional attribute
+// conditions, e.g. {"region": "us"}, that must be fulfilled in addition to its
+// segments.
+type ConditionalRequirement struct {
+	PermissionRequirement
+	Conditions map[string]string
+}
+
+// String returns the permission in its dotted form followed by its conditions,
+// e.g. `namespace.service.resource.read{"region"="us"}`.
+func (p ConditionalPermission) String() string {
+	return p.Permission.String() + conditionsString(p.Conditions)
+}
+
+// String returns the requirement in its dotted form followed by its
+// conditions, e.g. `namespace.service.resource.read{"region"="us"}`.
+func (r ConditionalRequirement) String() string {
+	return r.PermissionRequirement.String() + conditionsString(r.Conditions)
+}
+
+// conditionsString returns the conditions as quoted key=value pairs sorted by
+// key, or an empty string if there are none. Keys and values are quoted so
+// that different conditions never have the same string form.
+func conditionsString(conditions map[string]string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(conditions))
+	for k, v := range conditions {
+		pairs = append(pairs, strconv.Quote(k)+"="+strconv.Quote(v))
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// FulfillsRequirement returns true if the provided permission p fulfills the
+// conditional requirement r, see Matcher.FulfillsConditionalRequirement.
+func (r ConditionalRequirement) FulfillsRequirement(p ConditionalPermission) bool {
+	return Matcher{}.FulfillsConditionalRequirement(r, p)
+}
+
+// FulfilledByAny returns true if at least one of the provided permissions
+// fulfills the conditional requirement r.
+func (r ConditionalRequirement) FulfilledByAny(permissions []ConditionalPermission) bool {
+	for _, p := range permissions {
+		if r.FulfillsRequirement(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// FulfillsConditionalRequirement returns true if the segments of p fulfill the
+// segments of r and every condition of r is matched by the condition with the
+// same key in p, in the same way as the segments, so that a wildcard value in
+// p matches any value. Conditions of p that r does not have are ignored.
+func (m Matcher) FulfillsConditionalRequirement(r ConditionalRequirement, p ConditionalPermission) bool {
+	if !m.FulfillsRequirement(r.PermissionRequirement, p.Permission) {
+		return false
+	}
+	for k, required := range r.Conditions {
+		value, ok := p.Conditions[k]
+		if !ok || !m.matches(required, value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMatcher_FulfillsConditionalRequirement(t *testing.T) {
+	base := PermissionFromParts("namespace", "service", "resource", "read")
+
+	var testCases = []struct {
+		name        string
+		requirement map[string]string
+		permission  map[string]string
+		expected    bool
+	}{
+		{"no conditions", nil, nil, true},
+		{"permission conditions only", nil, map[string]string{"region": "us"}, true},
+		{"matching", map[string]string{"region": "us"}, map[string]string{"region": "us"}, true},
+		{"matching subset", map[string]string{"region": "us"}, map[string]string{"region": "us", "tier": "gold"}, true},
+		{"wildcard value", map[string]string{"region": "us"}, map[string]string{"region": Wildcard}, true},
+		{"different value", map[string]string{"region": "us"}, map[string]string{"region": "eu"}, false},
+		{"missing condition", map[string]string{"region": "us"}, nil, false},
+		{"partially matching", map[string]string{"region": "us", "tier": "gold"}, map[string]string{"region": "us", "tier": "silver"}, false},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			requirement := ConditionalRequirement{base.AsRequirement(), c.requirement}
+			permission := ConditionalPermission{base, c.permission}
+			require.Equal(t, c.expected, requirement.FulfillsRequirement(permission))
+			require.Equal(t, c.expected, Matcher{}.FulfillsConditionalRequirement(requirement, permission))
+			require.Equal(t, c.expected, requirement.FulfilledByAny([]ConditionalPermission{permission}))
+		})
+	}
+
+	// segments must still match when the conditions do
+	requirement := ConditionalRequirement{base.AsRequirement(), map[string]string{"region": "us"}}
+	write := PermissionFromParts("namespace", "service", "resource", "write")
+	require.False(t, requirement.FulfillsRequirement(ConditionalPermission{write, map[string]string{"region": "us"}}))
+	require.True(t, requirement.FulfillsRequirement(ConditionalPermission{PermissionFromParts("*", "*", "*", "*"), map[string]string{"region": "us"}}))
+	require.False(t, requirement.FulfillsRequirement(ConditionalPermission{PermissionFromParts("*", "*", "*", "*"), map[string]string{"region": "eu"}}))
+	require.True(t, Matcher{CaseInsensitive: true}.FulfillsConditionalRequirement(requirement, ConditionalPermission{base, map[string]string{"region": "US"}}))
+}
+
+func TestConditionalPermission_String(t *testing.T) {
+	base := PermissionFromParts("namespace", "service", "resource", "read")
+	require.Equal(t, "namespace.service.resource.read", ConditionalPermission{Permission: base}.String())
+	require.Equal(t, `namespace.service.resource.read{"region"="us"}`, ConditionalRequirement{base.AsRequirement(), map[string]string{"region": "us"}}.String())
+
+	// values containing separators do not collide with other conditions
+	a := ConditionalPermission{base, map[string]string{"a": "b,c=d"}}
+	b := ConditionalPermission{base, map[string]string{"a": "b", "c": "d"}}
+	require.NotEqual(t, a.String(), b.String())
+}
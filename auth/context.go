@@ -0,0 +1,20 @@
+package auth
+
+import "context"
+
+// permissionsKey is the context key used to store the caller's permissions.
+type permissionsKey struct{}
+
+// WithPermissions returns a copy of ctx that carries the provided permissions
+// so that handlers further down the chain can retrieve them with
+// PermissionsFromContext.
+func WithPermissions(ctx context.Context, perms []Permission) context.Context {
+	return context.WithValue(ctx, permissionsKey{}, perms)
+}
+
+// PermissionsFromContext returns the permissions stored in ctx by
+// WithPermissions and true, or nil and false if no permissions were stored.
+func PermissionsFromContext(ctx context.Context) ([]Permission, bool) {
+	perms, ok := ctx.Value(permissionsKey{}).([]Permission)
+	return perms, ok
+}
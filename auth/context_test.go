@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPermissionsFromContext(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		perms := []Permission{{"namespace", "service", "resource", "verb"}}
+		got, ok := PermissionsFromContext(WithPermissions(context.Background(), perms))
+		require.True(t, ok)
+		require.Equal(t, perms, got)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		got, ok := PermissionsFromContext(context.Background())
+		require.False(t, ok)
+		require.Nil(t, got)
+	})
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/clarkmcc/apiutils/errors"
+)
+
+// NewForbiddenForPermission returns a forbidden error for the permission
+// requirement that the caller failed to fulfill. The requirement is included
+// as the name of the details and as a PermissionRequired cause so that clients
+// can tell which permission is missing.
+func NewForbiddenForPermission(requirement PermissionRequirement) *errors.StatusError {
+	permission := requirement.String()
+	err := errors.NewForbidden(permission, fmt.Errorf("missing permission '%s'", permission))
+	err.ErrStatus.Details.Causes = append(err.ErrStatus.Details.Causes, errors.StatusCause{
+		Type:    errors.CauseTypePermissionRequired,
+		Message: fmt.Sprintf("permission '%s' is required", permission),
+		Value:   permission,
+	})
+	return err
+}
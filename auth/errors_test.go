@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewForbiddenForPermission(t *testing.T) {
+	err := NewForbiddenForPermission(ParsePermissionRequirementOrDie("namespace.service.resource.verb"))
+	require.True(t, errors.IsForbidden(err))
+	require.Equal(t, "namespace.service.resource.verb", err.ErrStatus.Details.Name)
+
+	cause, ok := errors.GetStatusCause(err, errors.CauseTypePermissionRequired)
+	require.True(t, ok)
+	require.Equal(t, "namespace.service.resource.verb", cause.Value)
+	require.Contains(t, cause.Message, "namespace.service.resource.verb")
+}
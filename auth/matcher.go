@@ -0,0 +1,53 @@
+package auth
+
+import "strings"
+
+// Matcher matches permissions against permission requirements. The zero value
+// matches the same way as PermissionRequirement.FulfillsRequirement.
+type Matcher struct {
+	// CaseInsensitive compares the segments of permissions and requirements
+	// using strings.EqualFold instead of exact equality.
+	CaseInsensitive bool
+	// RequirementWildcards allows requirements to contain wildcard segments,
+	// meaning that the segment is irrelevant to the requirement: a wildcard
+	// segment in the requirement matches any segment of the permission,
+	// including a wildcard. When unset, a wildcard segment in the requirement
+	// is compared like any other value and is only matched by a wildcard.
+	RequirementWildcards bool
+}
+
+// FulfillsRequirement returns true if the provided permission p fulfills the
+// permission requirement r. A wildcard segment in p always matches.
+func (m Matcher) FulfillsRequirement(r PermissionRequirement, p Permission) bool {
+	return m.matchesSegments(r.segments(), p)
+}
+
+// FulfillsPartialRequirement returns true if the provided permission p fulfills
+// the leading segments of the partial requirement r, in the same way as
+// FulfillsRequirement. The other segments of p are not compared.
+func (m Matcher) FulfillsPartialRequirement(r PartialRequirement, p Permission) bool {
+	return m.matchesSegments(r.segments(), p)
+}
+
+// matchesSegments returns true if the leading segments of p match each of the
+// required segments.
+func (m Matcher) matchesSegments(required []string, p Permission) bool {
+	granted := []string{p.Namespace, p.Service, p.Resource, p.Verb}
+	for i, required := range required {
+		if !m.matches(required, granted[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns true if the granted segment matches the required segment.
+func (m Matcher) matches(required, granted string) bool {
+	if granted == Wildcard || (m.RequirementWildcards && required == Wildcard) {
+		return true
+	}
+	if m.CaseInsensitive {
+		return strings.EqualFold(required, granted)
+	}
+	return required == granted
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMatcher_FulfillsRequirement(t *testing.T) {
+	var testCases = []struct {
+		requirement     string
+		permission      string
+		caseSensitive   bool
+		caseInsensitive bool
+	}{
+		{"namespace.service.resource.read", "namespace.service.resource.read", true, true},
+		{"namespace.service.resource.read", "namespace.service.resource.Read", false, true},
+		{"namespace.service.resource.read", "NAMESPACE.Service.Resource.READ", false, true},
+		{"namespace.service.resource.read", "namespace.service.resource.Write", false, false},
+		{"namespace.service.resource.read", "Namespace.*.Resource.*", false, true},
+		{"namespace.service.resource.read", "*.*.*.*", true, true},
+		{"namespace.service.resource.read", "Other.*.*.*", false, false},
+	}
+
+	for _, c := range testCases {
+		t.Run(fmt.Sprintf("%v_%v", c.requirement, c.permission), func(t *testing.T) {
+			permission, err := ParsePermissionString(c.permission)
+			require.NoError(t, err)
+			requirement := ParsePermissionRequirementOrDie(c.requirement)
+			require.Equal(t, c.caseSensitive, Matcher{}.FulfillsRequirement(requirement, permission))
+			require.Equal(t, c.caseSensitive, requirement.FulfillsRequirement(permission))
+			require.Equal(t, c.caseInsensitive, Matcher{CaseInsensitive: true}.FulfillsRequirement(requirement, permission))
+		})
+	}
+}
+
+func TestMatcher_RequirementWildcards(t *testing.T) {
+	// every combination of a required and a granted segment, in every position
+	var segments = []struct {
+		required         string
+		granted          string
+		withWildcards    bool
+		withoutWildcards bool
+	}{
+		{"value", "value", true, true},
+		{"value", "other", false, false},
+		{"value", Wildcard, true, true},
+		{Wildcard, "value", true, false},
+		{Wildcard, Wildcard, true, true},
+	}
+
+	for position := 0; position < 4; position++ {
+		for _, c := range segments {
+			required := []string{"namespace", "service", "resource", "verb"}
+			granted := []string{"namespace", "service", "resource", "verb"}
+			required[position], granted[position] = c.required, c.granted
+			requirement := PermissionRequirement(PermissionFromParts(required[0], required[1], required[2], required[3]))
+			permission := PermissionFromParts(granted[0], granted[1], granted[2], granted[3])
+
+			t.Run(fmt.Sprintf("%v_%v", requirement, permission), func(t *testing.T) {
+				require.Equal(t, c.withWildcards, Matcher{RequirementWildcards: true}.FulfillsRequirement(requirement, permission))
+				require.Equal(t, c.withoutWildcards, Matcher{}.FulfillsRequirement(requirement, permission))
+				require.Equal(t, c.withoutWildcards, requirement.FulfillsRequirement(permission))
+			})
+		}
+	}
+
+	// a mismatch in another segment is not hidden by a wildcard requirement
+	requirement := PermissionRequirement(PermissionFromParts("namespace", Wildcard, "resource", "verb"))
+	require.False(t, Matcher{RequirementWildcards: true}.FulfillsRequirement(requirement, PermissionFromParts("namespace", "service", "resource", "other")))
+	require.True(t, Matcher{RequirementWildcards: true, CaseInsensitive: true}.FulfillsRequirement(requirement, PermissionFromParts("Namespace", "service", "Resource", "VERB")))
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/clarkmcc/apiutils/httputils"
+	"net/http"
+)
+
+// PermissionExtractor returns the permissions that have been granted to the
+// caller of the provided request.
+type PermissionExtractor func(*http.Request) ([]Permission, error)
+
+// ExtractErrorObserver, when set, is called by RequirePermissions with the
+// request and the error when the permissions cannot be extracted, e.g. to log
+// why a token was rejected. The error itself is not sent to the client. It
+// must be set before any handlers run and be safe for concurrent use.
+var ExtractErrorObserver func(*http.Request, error)
+
+// unauthorizedMessage is the message written when the permissions cannot be
+// extracted.
+const unauthorizedMessage = "missing or invalid credentials"
+
+// RequirePermissions returns a middleware that only allows a request through
+// to the next handler if the caller's permissions fulfill every requirement in
+// the group. If the permissions cannot be extracted, an unauthorized error with
+// a fixed message is written, so that details of the failure are not leaked to
+// the client, see ExtractErrorObserver. If a requirement is not fulfilled, a
+// forbidden error is written.
+func RequirePermissions(group PermissionRequirementGroup, extract PermissionExtractor) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			permissions, err := extract(r)
+			if err != nil {
+				if ExtractErrorObserver != nil {
+					ExtractErrorObserver(r, err)
+				}
+				httputils.WriteError(errors.NewUnauthorized(unauthorizedMessage), w)
+				return
+			}
+			for _, requirement := range group {
+				if !requirement.FulfilledByAny(permissions) {
+					httputils.WriteError(NewForbiddenForPermission(requirement), w)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
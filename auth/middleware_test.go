@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequirePermissions(t *testing.T) {
+	group := NewPermissionRequirementGroup(
+		"namespace.service.resource.read",
+		"namespace.service.resource.write",
+	)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("Allow", func(t *testing.T) {
+		srv := httptest.NewServer(RequirePermissions(group, func(r *http.Request) ([]Permission, error) {
+			return []Permission{
+				{"namespace", "service", "resource", "read"},
+				{"namespace", "service", "resource", "write"},
+			}, nil
+		})(ok))
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		srv := httptest.NewServer(RequirePermissions(group, func(r *http.Request) ([]Permission, error) {
+			return []Permission{{"namespace", "service", "resource", "read"}}, nil
+		})(ok))
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL)
+		require.NoError(t, err)
+		err, hasError := errors.FromResponse(resp)
+		require.True(t, hasError)
+		require.True(t, errors.IsForbidden(err))
+		require.Contains(t, err.Error(), "namespace.service.resource.write")
+	})
+
+	t.Run("ExtractionFailure", func(t *testing.T) {
+		defer func(saved func(*http.Request, error)) { ExtractErrorObserver = saved }(ExtractErrorObserver)
+		var observed error
+		ExtractErrorObserver = func(r *http.Request, err error) {
+			observed = err
+		}
+
+		srv := httptest.NewServer(RequirePermissions(group, func(r *http.Request) ([]Permission, error) {
+			return nil, fmt.Errorf("parsing token: signature key abc123 rejected")
+		})(ok))
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL)
+		require.NoError(t, err)
+		err, hasError := errors.FromResponse(resp)
+		require.True(t, hasError)
+		require.True(t, errors.IsUnauthorized(err))
+		require.Equal(t, "missing or invalid credentials", errors.MessageForError(err))
+		require.Error(t, observed)
+		require.Contains(t, observed.Error(), "abc123")
+	})
+}
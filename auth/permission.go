@@ -2,6 +2,8 @@ package auth
 
 import (
 	"fmt"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sort"
 	"strings"
 )
 
@@ -12,7 +14,8 @@ const (
 // PermissionRequirement is a permission that is used as a requirement for
 // particular resources or endpoints that the caller needs to have in order
 // for us to allow the request. Permission requirements never have wildcards
-// and are intended to be explicit.
+// and are intended to be explicit, unless they are matched by a Matcher with
+// RequirementWildcards set.
 type PermissionRequirement Permission
 
 type Permission struct {
@@ -55,36 +58,386 @@ func ParsePermissionString(in string) (Permission, error) {
 	if len(parts) != 4 {
 		return Permission{}, fmt.Errorf("expected 4 parts, got %v", len(parts))
 	}
-	return Permission{parts[0], parts[1], parts[2], parts[3]}, nil
+	p := Permission{parts[0], parts[1], parts[2], parts[3]}
+	if err := p.Validate(); err != nil {
+		return Permission{}, fmt.Errorf("invalid permission '%s': %w", in, err)
+	}
+	return p, nil
+}
+
+// PartialRequirement is a permission requirement that only constrains its
+// leading segments, e.g. "namespace.service" for a coarse-grained check of a
+// service. The remaining segments are fulfilled by any value. Partial
+// requirements are kept out of PermissionRequirement so that an empty segment
+// of a PermissionRequirement is never mistaken for one that matches anything.
+type PartialRequirement struct {
+	PermissionRequirement
+
+	// n is the number of leading segments that are matched, where zero means
+	// that all four segments are matched.
+	n int
+}
+
+// ParsePermissionRequirement parses a requirement with between one and four
+// segments. The missing trailing segments are left empty and match any value,
+// so that "namespace.service" is fulfilled by "namespace.service.resource.verb".
+func ParsePermissionRequirement(in string) (PartialRequirement, error) {
+	if strings.Contains(in, Wildcard) {
+		return PartialRequirement{}, fmt.Errorf("permission requirements cannot contain '%v' character", Wildcard)
+	}
+	parts := strings.Split(in, ".")
+	if len(parts) > 4 {
+		return PartialRequirement{}, fmt.Errorf("expected at most 4 parts, got %v", len(parts))
+	}
+	fields := []string{FieldNamespace, FieldService, FieldResource, FieldVerb}
+	for i, part := range parts {
+		if len(part) == 0 {
+			return PartialRequirement{}, fmt.Errorf("invalid permission requirement '%s': %s segment is empty", in, fields[i])
+		}
+	}
+	segments := make([]string, 4)
+	copy(segments, parts)
+	return PartialRequirement{
+		PermissionRequirement: PermissionRequirement(PermissionFromParts(segments[0], segments[1], segments[2], segments[3])),
+		n:                     len(parts),
+	}, nil
+}
+
+// segments returns the leading segments of the requirement that are matched.
+func (r PartialRequirement) segments() []string {
+	segments := r.PermissionRequirement.segments()
+	if r.n > 0 && r.n < len(segments) {
+		return segments[:r.n]
+	}
+	return segments
+}
+
+// String returns the requirement in its dotted form, without the trailing
+// segments that are not matched.
+func (r PartialRequirement) String() string {
+	return strings.Join(r.segments(), ".")
 }
 
 // FulfillsRequirement returns true if the provided permission p fulfills the
-// permission requirement r.
-func (r PermissionRequirement) FulfillsRequirement(p Permission) bool {
-	if r.Namespace != p.Namespace && p.Namespace != Wildcard {
-		return false
+// leading segments of the partial requirement r. The other segments of p are
+// not compared.
+// Segments are compared case-sensitively, use a Matcher for other behavior.
+func (r PartialRequirement) FulfillsRequirement(p Permission) bool {
+	return Matcher{}.FulfillsPartialRequirement(r, p)
+}
+
+// FulfilledByAny returns true if at least one of the provided permissions
+// fulfills the partial requirement r.
+func (r PartialRequirement) FulfilledByAny(permissions []Permission) bool {
+	for _, p := range permissions {
+		if r.FulfillsRequirement(p) {
+			return true
+		}
 	}
-	if r.Service != p.Service && p.Service != Wildcard {
-		return false
+	return false
+}
+
+// PermissionFromParts returns the permission with the provided segments.
+func PermissionFromParts(namespace, service, resource, verb string) Permission {
+	return Permission{Namespace: namespace, Service: service, Resource: resource, Verb: verb}
+}
+
+// PermissionFromPath parses a permission from a path whose four segments are
+// separated by sep rather than '.', e.g. "/namespace/service/resource/verb"
+// with the separator "/". Leading and trailing separators are ignored.
+func PermissionFromPath(path string, sep string) (Permission, error) {
+	if len(sep) == 0 {
+		return Permission{}, fmt.Errorf("separator cannot be empty")
 	}
-	if r.Resource != p.Resource && p.Resource != Wildcard {
-		return false
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(path, sep), sep), sep)
+	if len(parts) != 4 {
+		return Permission{}, fmt.Errorf("expected 4 parts, got %v", len(parts))
 	}
-	if r.Verb != p.Verb && p.Verb != Wildcard {
-		return false
+	p := PermissionFromParts(parts[0], parts[1], parts[2], parts[3])
+	if err := p.Validate(); err != nil {
+		return Permission{}, fmt.Errorf("invalid permission path '%s': %w", path, err)
 	}
-	return true
+	return p, nil
+}
+
+// ParsePermissions parses every permission string in the input. The valid
+// permissions are always returned, and if any of the inputs are malformed, an
+// aggregate error describing each of them by index is returned as well.
+func ParsePermissions(in []string) ([]Permission, error) {
+	var (
+		out  []Permission
+		errs []error
+	)
+	for i, s := range in {
+		p, err := ParsePermissionString(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("permission %d: %w", i, err))
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, utilerrors.NewAggregate(errs)
+}
+
+// Validate returns an error if any of the segments of the permission are empty
+// or contain the '.' separator.
+func (r Permission) Validate() error {
+	segments := []struct {
+		name  string
+		value string
+	}{
+		{FieldNamespace, r.Namespace},
+		{FieldService, r.Service},
+		{FieldResource, r.Resource},
+		{FieldVerb, r.Verb},
+	}
+	for _, s := range segments {
+		if len(s.value) == 0 {
+			return fmt.Errorf("%s segment is empty", s.name)
+		}
+		if strings.Contains(s.value, ".") {
+			return fmt.Errorf("%s segment '%s' cannot contain '.'", s.name, s.value)
+		}
+	}
+	return nil
+}
+
+// FulfillsRequirement returns true if the provided permission p fulfills the
+// permission requirement r.
+// Segments are compared case-sensitively, use a Matcher for other behavior.
+func (r PermissionRequirement) FulfillsRequirement(p Permission) bool {
+	return Matcher{}.FulfillsRequirement(r, p)
+}
+
+// Grants returns true if the granted permission p grants the permission
+// requirement r. It is equivalent to r.FulfillsRequirement(p).
+func (p Permission) Grants(r PermissionRequirement) bool {
+	return r.FulfillsRequirement(p)
+}
+
+// Less returns true if p sorts before other. Permissions are compared segment
+// by segment, from the namespace to the verb, and a wildcard segment sorts
+// before any other value so that broader grants come first.
+func (p Permission) Less(other Permission) bool {
+	a := []string{p.Namespace, p.Service, p.Resource, p.Verb}
+	b := []string{other.Namespace, other.Service, other.Resource, other.Verb}
+	for i := range a {
+		switch {
+		case a[i] == b[i]:
+			continue
+		case a[i] == Wildcard:
+			return true
+		case b[i] == Wildcard:
+			return false
+		default:
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// SortPermissions sorts the permissions in place in the order defined by
+// Permission.Less, e.g. to store them in a canonical form.
+func SortPermissions(permissions []Permission) {
+	sort.SliceStable(permissions, func(i, j int) bool {
+		return permissions[i].Less(permissions[j])
+	})
 }
 
 func (r PermissionRequirement) String() string {
 	return Permission(r).String()
 }
 
+// segments returns the four segments of the requirement.
+func (r PermissionRequirement) segments() []string {
+	return []string{r.Namespace, r.Service, r.Resource, r.Verb}
+}
+
+// Names of the segments of a permission used by Fields and
+// PermissionRequirementFromFields.
+const (
+	FieldNamespace = "namespace"
+	FieldService   = "service"
+	FieldResource  = "resource"
+	FieldVerb      = "verb"
+)
+
+// Fields returns the segments of the requirement keyed by their names, e.g. to
+// display them in a UI.
+func (r PermissionRequirement) Fields() map[string]string {
+	return map[string]string{
+		FieldNamespace: r.Namespace,
+		FieldService:   r.Service,
+		FieldResource:  r.Resource,
+		FieldVerb:      r.Verb,
+	}
+}
+
+// PermissionRequirementFromFields is the inverse of Fields. It returns an error
+// if any of the segments are missing, unknown, invalid or a wildcard.
+func PermissionRequirementFromFields(fields map[string]string) (PermissionRequirement, error) {
+	var r PermissionRequirement
+	for name, value := range fields {
+		switch name {
+		case FieldNamespace:
+			r.Namespace = value
+		case FieldService:
+			r.Service = value
+		case FieldResource:
+			r.Resource = value
+		case FieldVerb:
+			r.Verb = value
+		default:
+			return PermissionRequirement{}, fmt.Errorf("unknown field '%s'", name)
+		}
+		if strings.Contains(value, Wildcard) {
+			return PermissionRequirement{}, fmt.Errorf("permission requirements cannot contain '%v' character", Wildcard)
+		}
+	}
+	if err := Permission(r).Validate(); err != nil {
+		return PermissionRequirement{}, err
+	}
+	return r, nil
+}
+
 type PermissionRequirementGroup []PermissionRequirement
 
+// NewPermissionRequirementGroup parses the requirements into a normalized
+// group, see Normalize. It panics if any of the requirements are invalid.
 func NewPermissionRequirementGroup(requirements ...string) (out PermissionRequirementGroup) {
 	for _, r := range requirements {
 		out = append(out, ParsePermissionRequirementOrDie(r))
 	}
-	return
+	return out.Normalize()
+}
+
+// Normalize returns a copy of the group without duplicate requirements, sorted
+// by their string form so that the group has a stable order.
+func (g PermissionRequirementGroup) Normalize() PermissionRequirementGroup {
+	if g == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(g))
+	out := make(PermissionRequirementGroup, 0, len(g))
+	for _, r := range g {
+		key := r.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
+// FulfilledByAny returns true if at least one of the provided permissions
+// fulfills the permission requirement r.
+func (r PermissionRequirement) FulfilledByAny(permissions []Permission) bool {
+	for _, p := range permissions {
+		if r.FulfillsRequirement(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// FulfilledBy returns true if every requirement in the group is fulfilled by
+// at least one of the provided permissions.
+func (g PermissionRequirementGroup) FulfilledBy(permissions []Permission) bool {
+	for _, r := range g {
+		if !r.FulfilledByAny(permissions) {
+			return false
+		}
+	}
+	return true
+}
+
+// EvaluatePermissions returns true if the granted permissions fulfill every
+// requirement in allows, the actions being requested, and none of those
+// actions is denied. Denies always take precedence: if a deny requirement
+// matches any of the allows, the evaluation fails regardless of the granted
+// permissions. Denies apply to the requested actions rather than to the
+// grants, so that "namespace.service.*.*" with a deny of
+// "namespace.service.resource.delete" allows everything in the service except
+// the delete.
+func EvaluatePermissions(allows, denies []PermissionRequirement, granted []Permission) bool {
+	for _, d := range denies {
+		for _, a := range allows {
+			if d.FulfillsRequirement(Permission(a)) {
+				return false
+			}
+		}
+	}
+	return PermissionRequirementGroup(allows).FulfilledBy(granted)
+}
+
+// CheckResult is the result of checking a single permission requirement.
+type CheckResult struct {
+	Requirement PermissionRequirement
+	// Fulfilled is true if at least one granted permission fulfills the
+	// requirement.
+	Fulfilled bool
+}
+
+// CheckAll checks every requirement against the granted permissions and returns
+// one result per requirement, in the same order, e.g. to show a caller which
+// of the requirements they are missing.
+func CheckAll(requirements []PermissionRequirement, granted []Permission) []CheckResult {
+	out := make([]CheckResult, 0, len(requirements))
+	for _, r := range requirements {
+		out = append(out, CheckResult{Requirement: r, Fulfilled: r.FulfilledByAny(granted)})
+	}
+	return out
+}
+
+// PermissionSet is a set of permissions that have been granted to a caller and
+// may contain wildcards.
+type PermissionSet []Permission
+
+// Expand returns the permissions in the catalog of concrete permissions that
+// are granted by at least one permission in the set, in catalog order.
+func (s PermissionSet) Expand(catalog []Permission) []Permission {
+	for _, p := range s {
+		if p.Namespace == Wildcard && p.Service == Wildcard && p.Resource == Wildcard && p.Verb == Wildcard {
+			return append([]Permission(nil), catalog...)
+		}
+	}
+	var out []Permission
+	for _, c := range catalog {
+		if PermissionRequirement(c).FulfilledByAny(s) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// DiffPermissionSets returns the permissions that are in new but not in old,
+// and those that are in old but not in new, e.g. to record the grants and
+// revocations in an audit log. The order of the sets does not matter, and
+// wildcard permissions are compared literally rather than expanded. Both
+// results are sorted by SortPermissions and contain no duplicates.
+func DiffPermissionSets(old, new PermissionSet) (added, removed []Permission) {
+	return subtractPermissions(new, old), subtractPermissions(old, new)
+}
+
+// subtractPermissions returns the sorted permissions of a that are not in b.
+func subtractPermissions(a, b PermissionSet) []Permission {
+	exclude := make(map[string]struct{}, len(b))
+	for _, p := range b {
+		exclude[p.String()] = struct{}{}
+	}
+	var out []Permission
+	for _, p := range a {
+		key := p.String()
+		if _, ok := exclude[key]; ok {
+			continue
+		}
+		exclude[key] = struct{}{}
+		out = append(out, p)
+	}
+	SortPermissions(out)
+	return out
 }
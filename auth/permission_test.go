@@ -3,32 +3,504 @@ package auth
 import (
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"math/rand"
 	"testing"
 )
 
+var fulfillsRequirementTestCases = []struct {
+	requirement string
+	permission  string
+	expected    bool
+}{
+	{"namespace.service.resource.verb", "namespace.service.resource.verb", true},
+	{"namespace.service.resource.verb", "namespace.service.resource.other", false},
+	{"namespace.service.resource.verb", "namespace.service.other.verb", false},
+	{"namespace.service.resource.verb", "namespace.other.resource.verb", false},
+	{"namespace.service.resource.verb", "other.service.resource.verb", false},
+	{"namespace.service.resource.verb", "namespace.service.resource.*", true},
+	{"namespace.service.resource.verb", "namespace.service.*.verb", true},
+	{"namespace.service.resource.verb", "namespace.*.resource.verb", true},
+	{"namespace.service.resource.verb", "*.service.resource.verb", true},
+	{"namespace.service.resource.verb", "*.*.*.*", true},
+}
+
 func TestPermissionRequirement_FulfillsRequirement(t *testing.T) {
+	for _, c := range fulfillsRequirementTestCases {
+		t.Run(fmt.Sprintf("%v_%v", c.requirement, c.permission), func(t *testing.T) {
+			permission, err := ParsePermissionString(c.permission)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, ParsePermissionRequirementOrDie(c.requirement).FulfillsRequirement(permission))
+		})
+	}
+}
+
+func TestPermission_Grants(t *testing.T) {
+	for _, c := range fulfillsRequirementTestCases {
+		t.Run(fmt.Sprintf("%v_%v", c.requirement, c.permission), func(t *testing.T) {
+			permission, err := ParsePermissionString(c.permission)
+			require.NoError(t, err)
+			requirement := ParsePermissionRequirementOrDie(c.requirement)
+			require.Equal(t, requirement.FulfillsRequirement(permission), permission.Grants(requirement))
+			require.Equal(t, c.expected, permission.Grants(requirement))
+		})
+	}
+}
+
+func TestNewPermissionRequirementGroup(t *testing.T) {
+	group := NewPermissionRequirementGroup(
+		"namespace.service.resource.write",
+		"namespace.service.resource.read",
+		"namespace.service.other.read",
+		"namespace.service.resource.write",
+		"namespace.service.resource.read",
+	)
+	require.Equal(t, PermissionRequirementGroup{
+		ParsePermissionRequirementOrDie("namespace.service.other.read"),
+		ParsePermissionRequirementOrDie("namespace.service.resource.read"),
+		ParsePermissionRequirementOrDie("namespace.service.resource.write"),
+	}, group)
+	require.Nil(t, NewPermissionRequirementGroup())
+}
+
+func TestPermissionRequirementGroup_Normalize(t *testing.T) {
+	group := PermissionRequirementGroup{
+		ParsePermissionRequirementOrDie("b.service.resource.verb"),
+		ParsePermissionRequirementOrDie("a.service.resource.verb"),
+		ParsePermissionRequirementOrDie("b.service.resource.verb"),
+	}
+	normalized := group.Normalize()
+	require.Equal(t, PermissionRequirementGroup{
+		ParsePermissionRequirementOrDie("a.service.resource.verb"),
+		ParsePermissionRequirementOrDie("b.service.resource.verb"),
+	}, normalized)
+	require.Len(t, group, 3, "the original group must not be modified")
+	require.Equal(t, normalized, normalized.Normalize())
+}
+
+func TestParsePermissionString(t *testing.T) {
 	var testCases = []struct {
-		requirement string
-		permission  string
-		expected    bool
+		in    string
+		valid bool
 	}{
-		{"namespace.service.resource.verb", "namespace.service.resource.verb", true},
-		{"namespace.service.resource.verb", "namespace.service.resource.other", false},
-		{"namespace.service.resource.verb", "namespace.service.other.verb", false},
-		{"namespace.service.resource.verb", "namespace.other.resource.verb", false},
-		{"namespace.service.resource.verb", "other.service.resource.verb", false},
-		{"namespace.service.resource.verb", "namespace.service.resource.*", true},
-		{"namespace.service.resource.verb", "namespace.service.*.verb", true},
-		{"namespace.service.resource.verb", "namespace.*.resource.verb", true},
-		{"namespace.service.resource.verb", "*.service.resource.verb", true},
-		{"namespace.service.resource.verb", "*.*.*.*", true},
+		{"namespace.service.resource.verb", true},
+		{"*.*.*.*", true},
+		{"namespace..resource.verb", false},
+		{".service.resource.verb", false},
+		{"namespace.service.resource.", false},
+		{"namespace.service.resource.verb.", false},
+		{".namespace.service.resource.verb", false},
+		{"namespace.service.resource", false},
+		{"...", false},
+		{"", false},
 	}
 
 	for _, c := range testCases {
-		t.Run(fmt.Sprintf("%v_%v", c.requirement, c.permission), func(t *testing.T) {
+		t.Run(c.in, func(t *testing.T) {
+			_, err := ParsePermissionString(c.in)
+			if c.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestParsePermissionRequirement(t *testing.T) {
+	var testCases = []struct {
+		in       string
+		expected PermissionRequirement
+		valid    bool
+	}{
+		{"namespace.service.resource.verb", PermissionRequirement{"namespace", "service", "resource", "verb"}, true},
+		{"namespace.service.resource", PermissionRequirement{"namespace", "service", "resource", ""}, true},
+		{"namespace.service", PermissionRequirement{"namespace", "service", "", ""}, true},
+		{"namespace", PermissionRequirement{"namespace", "", "", ""}, true},
+		{"namespace.service.*", PermissionRequirement{}, false},
+		{"namespace..resource", PermissionRequirement{}, false},
+		{"namespace.service.", PermissionRequirement{}, false},
+		{"namespace.service.resource.verb.extra", PermissionRequirement{}, false},
+		{"", PermissionRequirement{}, false},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.in, func(t *testing.T) {
+			r, err := ParsePermissionRequirement(c.in)
+			if !c.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, r.PermissionRequirement)
+			require.Equal(t, c.in, r.String())
+		})
+	}
+}
+
+func TestPermissionRequirement_Partial(t *testing.T) {
+	var testCases = []struct {
+		permission string
+		expected   bool
+	}{
+		{"namespace.service.resource.verb", true},
+		{"namespace.service.other.other", true},
+		{"namespace.service.*.*", true},
+		{"namespace.*.resource.verb", true},
+		{"*.*.*.*", true},
+		{"namespace.other.resource.verb", false},
+		{"other.service.resource.verb", false},
+	}
+
+	partial, err := ParsePermissionRequirement("namespace.service")
+	require.NoError(t, err)
+	for _, c := range testCases {
+		t.Run(c.permission, func(t *testing.T) {
 			permission, err := ParsePermissionString(c.permission)
 			require.NoError(t, err)
-			require.Equal(t, c.expected, ParsePermissionRequirementOrDie(c.requirement).FulfillsRequirement(permission))
+			require.Equal(t, c.expected, partial.FulfillsRequirement(permission))
+			require.Equal(t, c.expected, Matcher{CaseInsensitive: true}.FulfillsPartialRequirement(partial, permission))
+			require.Equal(t, c.expected, partial.FulfilledByAny([]Permission{permission}))
+
+			// a partial requirement is fulfilled exactly when a requirement with
+			// the trailing segments of the permission would be
+			explicit := PermissionRequirement{"namespace", "service", permission.Resource, permission.Verb}
+			require.Equal(t, explicit.FulfillsRequirement(permission), partial.FulfillsRequirement(permission))
+		})
+	}
+
+	// empty segments of requirements that are not partial only match empty
+	// or wildcard segments
+	permission := PermissionFromParts("namespace", "service", "resource", "delete")
+	require.False(t, PermissionRequirement{}.FulfillsRequirement(permission))
+	require.False(t, PermissionRequirement{Namespace: "namespace", Service: "service", Resource: "resource"}.FulfillsRequirement(permission))
+	require.False(t, Permission{Namespace: "namespace", Service: "service"}.AsRequirement().FulfillsRequirement(permission))
+	require.True(t, PermissionRequirement{Namespace: "namespace", Service: "service", Resource: "resource"}.FulfillsRequirement(PermissionFromParts("namespace", "service", "resource", "*")))
+	require.Equal(t, "namespace.service.resource.", PermissionRequirement{Namespace: "namespace", Service: "service", Resource: "resource"}.String())
+	require.False(t, PartialRequirement{PermissionRequirement: PermissionRequirement{Namespace: "namespace", Service: "service"}}.FulfillsRequirement(permission))
+
+	// parsing a requirement does not change how permissions compare
+	parsed, err := ParsePermissionRequirement("namespace.service.resource.delete")
+	require.NoError(t, err)
+	require.True(t, parsed.PermissionRequirement == permission.AsRequirement())
+}
+
+func TestPermissionFromParts(t *testing.T) {
+	p := PermissionFromParts("namespace", "service", "resource", "verb")
+	require.Equal(t, "namespace.service.resource.verb", p.String())
+	require.NoError(t, p.Validate())
+}
+
+func TestPermissionFromPath(t *testing.T) {
+	var testCases = []struct {
+		path     string
+		sep      string
+		expected string
+		valid    bool
+	}{
+		{"/namespace/service/resource/verb", "/", "namespace.service.resource.verb", true},
+		{"namespace/service/resource/verb/", "/", "namespace.service.resource.verb", true},
+		{"namespace:service:resource:*", ":", "namespace.service.resource.*", true},
+		{"namespace.service.resource.verb", ".", "namespace.service.resource.verb", true},
+		{"/namespace/service/resource", "/", "", false},
+		{"/namespace/service/resource/verb/extra", "/", "", false},
+		{"/namespace//resource/verb", "/", "", false},
+		{"/namespace/service.name/resource/verb", "/", "", false},
+		{"/namespace/service/resource/verb", "", "", false},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.path, func(t *testing.T) {
+			p, err := PermissionFromPath(c.path, c.sep)
+			if !c.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, p.String())
+		})
+	}
+}
+
+func TestPermissionRequirement_RoundTrip(t *testing.T) {
+	inputs := []string{
+		"namespace.service.resource.verb",
+		"my-namespace.my_service.resource-v2.list",
+		"ns:prod.files/v1.objects[0].get-all",
+		"名前空間.サービス.リソース.読む",
+		"a.b.c.d",
+		" a. b .c .d ",
+		"n%2e.s@x.r+1.v=2",
+	}
+	for _, in := range inputs {
+		t.Run(in, func(t *testing.T) {
+			p, err := ParsePermissionString(in)
+			require.NoError(t, err)
+			require.Equal(t, in, p.String())
+
+			requirement := ParsePermissionRequirementOrDie(in)
+			require.Equal(t, in, requirement.String())
+
+			fields := requirement.Fields()
+			require.Len(t, fields, 4)
+			require.Equal(t, requirement.Namespace, fields[FieldNamespace])
+			require.Equal(t, requirement.Verb, fields[FieldVerb])
+
+			fromFields, err := PermissionRequirementFromFields(fields)
+			require.NoError(t, err)
+			require.Equal(t, requirement, fromFields)
+			require.Equal(t, in, fromFields.String())
+		})
+	}
+}
+
+func TestPermissionRequirementFromFields(t *testing.T) {
+	_, err := PermissionRequirementFromFields(map[string]string{
+		FieldNamespace: "namespace", FieldService: "service", FieldResource: "resource",
+	})
+	require.EqualError(t, err, "verb segment is empty")
+
+	_, err = PermissionRequirementFromFields(map[string]string{
+		FieldNamespace: "namespace", FieldService: "service", FieldResource: "resource", FieldVerb: "verb", "other": "x",
+	})
+	require.EqualError(t, err, "unknown field 'other'")
+
+	_, err = PermissionRequirementFromFields(map[string]string{
+		FieldNamespace: "namespace", FieldService: "service", FieldResource: "resource", FieldVerb: "*",
+	})
+	require.Error(t, err)
+
+	_, err = PermissionRequirementFromFields(map[string]string{
+		FieldNamespace: "namespace", FieldService: "service", FieldResource: "re.source", FieldVerb: "verb",
+	})
+	require.Error(t, err)
+}
+
+func TestPermission_Validate(t *testing.T) {
+	require.NoError(t, Permission{"namespace", "service", "resource", "verb"}.Validate())
+	require.Error(t, Permission{"namespace", "", "resource", "verb"}.Validate())
+	require.Error(t, Permission{"namespace", "service", "resource.other", "verb"}.Validate())
+	require.Error(t, Permission{}.Validate())
+}
+
+func TestEvaluatePermissions(t *testing.T) {
+	parse := func(in ...string) (out []Permission) {
+		for _, s := range in {
+			p, err := ParsePermissionString(s)
+			require.NoError(t, err)
+			out = append(out, p)
+		}
+		return
+	}
+
+	var testCases = []struct {
+		name     string
+		allows   PermissionRequirementGroup
+		denies   PermissionRequirementGroup
+		granted  []Permission
+		expected bool
+	}{
+		{
+			name:     "AllowOnly",
+			allows:   NewPermissionRequirementGroup("namespace.service.resource.read"),
+			granted:  parse("namespace.service.resource.read"),
+			expected: true,
+		},
+		{
+			name:     "AllowOnlyMissing",
+			allows:   NewPermissionRequirementGroup("namespace.service.resource.write"),
+			granted:  parse("namespace.service.resource.read"),
+			expected: false,
+		},
+		{
+			name:     "DenyOverride",
+			allows:   NewPermissionRequirementGroup("namespace.service.resource.read", "namespace.service.resource.delete"),
+			denies:   NewPermissionRequirementGroup("namespace.service.resource.delete"),
+			granted:  parse("namespace.service.resource.read", "namespace.service.resource.delete"),
+			expected: false,
+		},
+		{
+			name:     "DenyNotRequested",
+			allows:   NewPermissionRequirementGroup("namespace.service.resource.read"),
+			denies:   NewPermissionRequirementGroup("namespace.service.resource.delete"),
+			granted:  parse("namespace.service.resource.read", "namespace.service.resource.delete"),
+			expected: true,
+		},
+		{
+			name:     "DenyNotGranted",
+			allows:   NewPermissionRequirementGroup("namespace.service.resource.read"),
+			denies:   NewPermissionRequirementGroup("namespace.service.resource.delete"),
+			granted:  parse("namespace.service.resource.read"),
+			expected: true,
+		},
+		{
+			name:     "WildcardDeny",
+			allows:   NewPermissionRequirementGroup("namespace.service.resource.read"),
+			denies:   NewPermissionRequirementGroup("namespace.service.resource.delete"),
+			granted:  parse("namespace.service.*.*"),
+			expected: true,
+		},
+		{
+			name:     "WildcardDenyRequested",
+			allows:   NewPermissionRequirementGroup("namespace.service.resource.delete"),
+			denies:   NewPermissionRequirementGroup("namespace.service.resource.delete"),
+			granted:  parse("namespace.service.*.*"),
+			expected: false,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, EvaluatePermissions(c.allows, c.denies, c.granted))
+		})
+	}
+}
+
+func TestSortPermissions(t *testing.T) {
+	expected := []Permission{
+		PermissionFromParts(Wildcard, Wildcard, Wildcard, Wildcard),
+		PermissionFromParts("!special", "service", "resource", "read"),
+		PermissionFromParts("a", Wildcard, "resource", "read"),
+		PermissionFromParts("a", "service", Wildcard, Wildcard),
+		PermissionFromParts("a", "service", Wildcard, "read"),
+		PermissionFromParts("a", "service", "resource", Wildcard),
+		PermissionFromParts("a", "service", "resource", "read"),
+		PermissionFromParts("a", "service", "resource", "write"),
+		PermissionFromParts("a", "service", "resources", "read"),
+		PermissionFromParts("b", "service", "resource", "read"),
+	}
+
+	shuffled := append([]Permission(nil), expected...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	require.NotEqual(t, expected, shuffled)
+	SortPermissions(shuffled)
+	require.Equal(t, expected, shuffled)
+
+	for i := range expected {
+		require.False(t, expected[i].Less(expected[i]))
+		for j := i + 1; j < len(expected); j++ {
+			require.True(t, expected[i].Less(expected[j]), "%v < %v", expected[i], expected[j])
+			require.False(t, expected[j].Less(expected[i]), "%v < %v", expected[j], expected[i])
+		}
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	granted := []Permission{
+		{"namespace", "service", "resource", "read"},
+		PermissionFromParts("namespace", "other", "*", "*"),
+	}
+	requirements := []PermissionRequirement{
+		ParsePermissionRequirementOrDie("namespace.service.resource.read"),
+		ParsePermissionRequirementOrDie("namespace.service.resource.write"),
+		ParsePermissionRequirementOrDie("namespace.other.resource.write"),
+		ParsePermissionRequirementOrDie("other.service.resource.read"),
+	}
+
+	require.Equal(t, []CheckResult{
+		{Requirement: requirements[0], Fulfilled: true},
+		{Requirement: requirements[1], Fulfilled: false},
+		{Requirement: requirements[2], Fulfilled: true},
+		{Requirement: requirements[3], Fulfilled: false},
+	}, CheckAll(requirements, granted))
+	require.Empty(t, CheckAll(nil, granted))
+}
+
+func TestPermissionSet_Expand(t *testing.T) {
+	catalog := []Permission{
+		{"namespace", "files", "document", "read"},
+		{"namespace", "files", "document", "write"},
+		{"namespace", "files", "folder", "read"},
+		{"namespace", "users", "user", "read"},
+		{"other", "files", "document", "read"},
+	}
+
+	var testCases = []struct {
+		name     string
+		set      PermissionSet
+		expected []Permission
+	}{
+		{
+			name:     "Concrete",
+			set:      PermissionSet{{"namespace", "files", "document", "read"}},
+			expected: []Permission{catalog[0]},
+		},
+		{
+			name:     "PartialWildcard",
+			set:      PermissionSet{{"namespace", "files", "*", "read"}},
+			expected: []Permission{catalog[0], catalog[2]},
+		},
+		{
+			name:     "OverlappingWildcards",
+			set:      PermissionSet{{"namespace", "*", "*", "read"}, {"*", "files", "document", "*"}},
+			expected: []Permission{catalog[0], catalog[1], catalog[2], catalog[3], catalog[4]},
+		},
+		{
+			name:     "FullWildcard",
+			set:      PermissionSet{{"namespace", "users", "user", "read"}, {"*", "*", "*", "*"}},
+			expected: catalog,
+		},
+		{
+			name:     "NoMatches",
+			set:      PermissionSet{{"missing", "*", "*", "*"}},
+			expected: nil,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, c.set.Expand(catalog))
+		})
+	}
+}
+
+func TestParsePermissions(t *testing.T) {
+	permissions, err := ParsePermissions([]string{
+		"namespace.service.resource.read",
+		"namespace..resource.read",
+		"namespace.service.resource.write",
+		"namespace.service",
+	})
+	require.Equal(t, []Permission{
+		PermissionFromParts("namespace", "service", "resource", "read"),
+		{"namespace", "service", "resource", "write"},
+	}, permissions)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "permission 1: ")
+	require.Contains(t, err.Error(), "permission 3: expected 4 parts, got 2")
+
+	permissions, err = ParsePermissions([]string{"namespace.service.resource.read"})
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+}
+
+func TestDiffPermissionSets(t *testing.T) {
+	read := PermissionFromParts("namespace", "service", "resource", "read")
+	write := PermissionFromParts("namespace", "service", "resource", "write")
+	all := PermissionFromParts("namespace", "service", "resource", Wildcard)
+	other := PermissionFromParts("other", "service", "resource", "read")
+
+	var testCases = []struct {
+		name            string
+		old             PermissionSet
+		new             PermissionSet
+		expectedAdded   []Permission
+		expectedRemoved []Permission
+	}{
+		{"identical", PermissionSet{read, write}, PermissionSet{write, read}, nil, nil},
+		{"overlapping", PermissionSet{read, write}, PermissionSet{write, other, read}, []Permission{other}, nil},
+		{"disjoint", PermissionSet{read, write}, PermissionSet{other}, []Permission{other}, []Permission{read, write}},
+		{"wildcards are literal", PermissionSet{read, write}, PermissionSet{all}, []Permission{all}, []Permission{read, write}},
+		{"duplicates", PermissionSet{read, read}, PermissionSet{write, write}, []Permission{write}, []Permission{read}},
+		{"empty", nil, PermissionSet{read}, []Permission{read}, nil},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			added, removed := DiffPermissionSets(c.old, c.new)
+			require.Equal(t, c.expectedAdded, added)
+			require.Equal(t, c.expectedRemoved, removed)
 		})
 	}
 }
@@ -0,0 +1,30 @@
+package auth
+
+import "fmt"
+
+// RoleResolver resolves a role name to the permissions that it grants.
+type RoleResolver interface {
+	Resolve(role string) ([]Permission, error)
+}
+
+// ResolvePermissions resolves each of the roles with r and returns the
+// permissions that they grant, without duplicates, in the order that they were
+// first resolved. An error is returned if any of the roles fails to resolve.
+func ResolvePermissions(roles []string, r RoleResolver) ([]Permission, error) {
+	var out []Permission
+	seen := make(map[Permission]struct{})
+	for _, role := range roles {
+		permissions, err := r.Resolve(role)
+		if err != nil {
+			return nil, fmt.Errorf("resolving role '%s': %w", role, err)
+		}
+		for _, p := range permissions {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type mapRoleResolver map[string][]string
+
+func (m mapRoleResolver) Resolve(role string) ([]Permission, error) {
+	in, ok := m[role]
+	if !ok {
+		return nil, fmt.Errorf("unknown role")
+	}
+	return ParsePermissions(in)
+}
+
+func TestResolvePermissions(t *testing.T) {
+	resolver := mapRoleResolver{
+		"viewer": {"namespace.service.resource.read", "namespace.service.other.read"},
+		"editor": {"namespace.service.resource.read", "namespace.service.resource.write"},
+	}
+
+	permissions, err := ResolvePermissions([]string{"viewer", "editor"}, resolver)
+	require.NoError(t, err)
+	expected, err := ParsePermissions([]string{
+		"namespace.service.resource.read",
+		"namespace.service.other.read",
+		"namespace.service.resource.write",
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, permissions)
+
+	permissions, err = ResolvePermissions(nil, resolver)
+	require.NoError(t, err)
+	require.Empty(t, permissions)
+
+	_, err = ResolvePermissions([]string{"viewer", "admin"}, resolver)
+	require.EqualError(t, err, "resolving role 'admin': unknown role")
+}
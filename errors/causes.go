@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// FieldCause returns a cause reporting that the field at path, in the dotted
+// and indexed notation of StatusCause.Field, e.g. "spec.containers[0].image",
+// has an invalid value.
+func FieldCause(path string, message string) StatusCause {
+	return StatusCause{
+		Type:    CauseTypeFieldValueInvalid,
+		Message: message,
+		Field:   path,
+	}
+}
+
+// CauseList accumulates the causes of a validation error, so that handlers can
+// collect the errors of nested fields without importing the field package:
+//
+//	var causes errors.CauseList
+//	causes.Add("spec.containers[0].image", "must not be empty")
+//	if len(causes) > 0 {
+//		return causes.Invalid("deployment")
+//	}
+type CauseList []StatusCause
+
+// Add appends a FieldCause for the field at path to the list.
+func (l *CauseList) Add(path string, message string) {
+	*l = append(*l, FieldCause(path, message))
+}
+
+// Invalid returns an invalid error for the item with the causes in the list, see
+// NewInvalidFromCauses. It always returns an error, callers should check that the
+// list is not empty first.
+func (l CauseList) Invalid(name string) *StatusError {
+	causes := make([]StatusCause, len(l))
+	copy(causes, l)
+	return NewInvalidFromCauses(name, causes)
+}
+
+// CausesFromErrors converts each of the errors collected during validation into
+// a cause with the error message. A *field.Error in the chain of an error keeps
+// its type and field, any other error becomes a FieldValueInvalid cause without
+// a field. Nil errors are skipped.
+func CausesFromErrors(errs []error) []StatusCause {
+	causes := make([]StatusCause, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var fieldErr *field.Error
+		if errors.As(err, &fieldErr) {
+			causes = append(causes, StatusCause{
+				Type:    CauseType(fieldErr.Type),
+				Message: fieldErr.ErrorBody(),
+				Field:   fieldErr.Field,
+				Value:   causeValue(fieldErr.BadValue),
+			})
+			continue
+		}
+		causes = append(causes, StatusCause{
+			Type:    CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+		})
+	}
+	return causes
+}
+
+// NewInvalidFromErrors returns an error indicating the item is invalid and cannot be processed,
+// with the causes converted from errs by CausesFromErrors.
+func NewInvalidFromErrors(name string, errs []error) *StatusError {
+	return NewInvalidFromCauses(name, CausesFromErrors(errs))
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"reflect"
+	"testing"
+)
+
+func TestCauseList(t *testing.T) {
+	var causes CauseList
+	causes.Add("spec.template.containers[0].image", "must not be empty")
+	causes.Add("spec.template.containers[1].ports[0].containerPort", "must be between 1 and 65535")
+
+	err := causes.Invalid("deployment")
+	if !IsInvalid(err) {
+		t.Errorf("expected to be %s", StatusReasonInvalid)
+	}
+	expected := []StatusCause{
+		{Type: CauseTypeFieldValueInvalid, Message: "must not be empty", Field: "spec.template.containers[0].image"},
+		{Type: CauseTypeFieldValueInvalid, Message: "must be between 1 and 65535", Field: "spec.template.containers[1].ports[0].containerPort"},
+	}
+	if !reflect.DeepEqual(expected, err.ErrStatus.Details.Causes) {
+		t.Errorf("expected causes %#v, got %#v", expected, err.ErrStatus.Details.Causes)
+	}
+	if err.ErrStatus.Details.Name != "deployment" {
+		t.Errorf("expected name %q, got %q", "deployment", err.ErrStatus.Details.Name)
+	}
+	expectedMessage := "deployment is invalid: [spec.template.containers[0].image: must not be empty, spec.template.containers[1].ports[0].containerPort: must be between 1 and 65535]"
+	if err.Error() != expectedMessage {
+		t.Errorf("expected message %q, got %q", expectedMessage, err.Error())
+	}
+
+	// the error does not share the causes with the list
+	causes[0].Message = "changed"
+	if err.ErrStatus.Details.Causes[0].Message != "must not be empty" {
+		t.Errorf("expected the error to be unaffected by changes to the list")
+	}
+}
+
+func TestFieldCause(t *testing.T) {
+	expected := StatusCause{Type: CauseTypeFieldValueInvalid, Message: "is required", Field: "metadata.name"}
+	if cause := FieldCause("metadata.name", "is required"); !reflect.DeepEqual(expected, cause) {
+		t.Errorf("expected %#v, got %#v", expected, cause)
+	}
+}
+
+func TestNewInvalidFromErrors(t *testing.T) {
+	errs := []error{
+		errors.New("name must not be empty"),
+		nil,
+		fmt.Errorf("checking labels: %w", errors.New("too many labels")),
+		field.Required(field.NewPath("spec", "replicas"), ""),
+	}
+
+	expected := []StatusCause{
+		{Type: CauseTypeFieldValueInvalid, Message: "name must not be empty"},
+		{Type: CauseTypeFieldValueInvalid, Message: "checking labels: too many labels"},
+		{Type: CauseTypeFieldValueRequired, Message: "Required value", Field: "spec.replicas"},
+	}
+	if causes := CausesFromErrors(errs); !reflect.DeepEqual(expected, causes) {
+		t.Errorf("expected causes %#v, got %#v", expected, causes)
+	}
+
+	err := NewInvalidFromErrors("deployment", errs)
+	if !IsInvalid(err) {
+		t.Errorf("expected to be %s", StatusReasonInvalid)
+	}
+	if !reflect.DeepEqual(expected, err.ErrStatus.Details.Causes) {
+		t.Errorf("expected causes %#v, got %#v", expected, err.ErrStatus.Details.Causes)
+	}
+	expectedMessage := "deployment is invalid: [name must not be empty, checking labels: too many labels, spec.replicas: Required value]"
+	if err.Error() != expectedMessage {
+		t.Errorf("expected message %q, got %q", expectedMessage, err.Error())
+	}
+}
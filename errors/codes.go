@@ -0,0 +1,201 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// reasonCodes maps each known StatusReason to the HTTP status code that it must
+// be paired with. It is the single source of truth for HTTPCodeForReason.
+var reasonCodes = map[StatusReason]int{
+	StatusReasonUnauthorized:          http.StatusUnauthorized,
+	StatusReasonForbidden:             http.StatusForbidden,
+	StatusReasonNotFound:              http.StatusNotFound,
+	StatusReasonAlreadyExists:         http.StatusConflict,
+	StatusReasonConflict:              http.StatusConflict,
+	StatusReasonPreconditionFailed:    http.StatusPreconditionFailed,
+	StatusReasonInvalid:               http.StatusUnprocessableEntity,
+	StatusReasonServerTimeout:         http.StatusInternalServerError,
+	StatusReasonTimeout:               http.StatusGatewayTimeout,
+	StatusReasonRequestTimeout:        http.StatusRequestTimeout,
+	StatusReasonClientClosedRequest:   StatusClientClosedRequest,
+	StatusReasonTooManyRequests:       http.StatusTooManyRequests,
+	StatusReasonBadRequest:            http.StatusBadRequest,
+	StatusReasonMethodNotAllowed:      http.StatusMethodNotAllowed,
+	StatusReasonNotAcceptable:         http.StatusNotAcceptable,
+	StatusReasonRequestEntityTooLarge: http.StatusRequestEntityTooLarge,
+	StatusReasonUnsupportedMediaType:  http.StatusUnsupportedMediaType,
+	StatusReasonPaymentRequired:       http.StatusPaymentRequired,
+	StatusReasonLocked:                http.StatusLocked,
+	StatusReasonFailedDependency:      http.StatusFailedDependency,
+	StatusReasonInternalError:         http.StatusInternalServerError,
+	StatusReasonServiceUnavailable:    http.StatusServiceUnavailable,
+}
+
+// customReason is a vendor-specific reason registered with RegisterReason.
+type customReason struct {
+	code      int
+	retryable bool
+}
+
+var (
+	customReasonsLock sync.RWMutex
+	customReasons     = map[StatusReason]customReason{}
+)
+
+// RegisterReason registers a vendor-specific reason with the HTTP status code
+// that it must be paired with, and whether errors with the reason may succeed
+// if retried. The registration is used by HTTPCodeForReason, ErrorToAPIStatus,
+// ValidateStatus and IsRetryable. Registering a reason again replaces the
+// previous registration. It panics if the reason is one of the built-in
+// reasons, which cannot be changed, or if the code is not a 4xx or 5xx code.
+func RegisterReason(reason StatusReason, code int, retryable bool) {
+	if _, ok := reasonCodes[reason]; ok || reason == StatusReasonUnknown {
+		panic(fmt.Sprintf("errors: cannot register built-in reason %q", reason))
+	}
+	if code < 400 || code > 599 {
+		panic(fmt.Sprintf("errors: reason %q must have a 4xx or 5xx code, got %d", reason, code))
+	}
+	customReasonsLock.Lock()
+	defer customReasonsLock.Unlock()
+	customReasons[reason] = customReason{code: code, retryable: retryable}
+}
+
+// lookupReason returns the code for a built-in or registered reason, and
+// whether the reason is known.
+func lookupReason(reason StatusReason) (int, bool) {
+	if code, ok := reasonCodes[reason]; ok {
+		return code, true
+	}
+	customReasonsLock.RLock()
+	defer customReasonsLock.RUnlock()
+	custom, ok := customReasons[reason]
+	return custom.code, ok
+}
+
+// registeredRetryable returns whether errors with a registered reason are
+// retryable, and whether the reason was registered with RegisterReason.
+func registeredRetryable(reason StatusReason) (bool, bool) {
+	customReasonsLock.RLock()
+	defer customReasonsLock.RUnlock()
+	custom, ok := customReasons[reason]
+	return custom.retryable, ok
+}
+
+// HTTPCodeForReason returns the HTTP status code that the reason maps to, or
+// 500 for StatusReasonUnknown and any reason that is neither built-in nor
+// registered with RegisterReason.
+func HTTPCodeForReason(reason StatusReason) int {
+	if code, ok := lookupReason(reason); ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// ReasonForHTTPCode returns the StatusReason a client should infer from a
+// server response with the given HTTP status code when the server did not
+// return a Status. The verb disambiguates a 409 to a POST, which is reported
+// as StatusReasonAlreadyExists. Unrecognized 5xx codes map to
+// StatusReasonInternalError and anything else to StatusReasonUnknown.
+func ReasonForHTTPCode(code int, verb string) StatusReason {
+	switch code {
+	case http.StatusConflict:
+		if verb == "POST" {
+			return StatusReasonAlreadyExists
+		}
+		return StatusReasonConflict
+	case http.StatusNotFound:
+		return StatusReasonNotFound
+	case http.StatusPreconditionFailed:
+		return StatusReasonPreconditionFailed
+	case http.StatusBadRequest:
+		return StatusReasonBadRequest
+	case http.StatusUnauthorized:
+		return StatusReasonUnauthorized
+	case http.StatusForbidden:
+		return StatusReasonForbidden
+	case http.StatusNotAcceptable:
+		return StatusReasonNotAcceptable
+	case http.StatusUnsupportedMediaType:
+		return StatusReasonUnsupportedMediaType
+	case http.StatusMethodNotAllowed:
+		return StatusReasonMethodNotAllowed
+	case http.StatusUnprocessableEntity:
+		return StatusReasonInvalid
+	case http.StatusServiceUnavailable:
+		return StatusReasonServiceUnavailable
+	case http.StatusRequestTimeout:
+		return StatusReasonRequestTimeout
+	case http.StatusGatewayTimeout:
+		return StatusReasonTimeout
+	case http.StatusTooManyRequests:
+		return StatusReasonTooManyRequests
+	case http.StatusPaymentRequired:
+		return StatusReasonPaymentRequired
+	case http.StatusLocked:
+		return StatusReasonLocked
+	case http.StatusFailedDependency:
+		return StatusReasonFailedDependency
+	case StatusClientClosedRequest:
+		return StatusReasonClientClosedRequest
+	}
+	if code >= 500 {
+		return StatusReasonInternalError
+	}
+	return StatusReasonUnknown
+}
+
+// machineCodes maps each known StatusReason to its machine code. The codes are
+// part of the API contract and must never change once released.
+var machineCodes = map[StatusReason]string{
+	StatusReasonUnknown:               "unknown",
+	StatusReasonUnauthorized:          "auth.unauthorized",
+	StatusReasonForbidden:             "auth.forbidden",
+	StatusReasonNotFound:              "resource.not_found",
+	StatusReasonAlreadyExists:         "resource.already_exists",
+	StatusReasonConflict:              "resource.conflict",
+	StatusReasonPreconditionFailed:    "resource.precondition_failed",
+	StatusReasonLocked:                "resource.locked",
+	StatusReasonFailedDependency:      "resource.failed_dependency",
+	StatusReasonInvalid:               "request.invalid",
+	StatusReasonBadRequest:            "request.bad_request",
+	StatusReasonMethodNotAllowed:      "request.method_not_allowed",
+	StatusReasonNotAcceptable:         "request.not_acceptable",
+	StatusReasonRequestEntityTooLarge: "request.entity_too_large",
+	StatusReasonUnsupportedMediaType:  "request.unsupported_media_type",
+	StatusReasonRequestTimeout:        "request.timeout",
+	StatusReasonClientClosedRequest:   "request.client_closed",
+	StatusReasonTooManyRequests:       "quota.too_many_requests",
+	StatusReasonPaymentRequired:       "quota.payment_required",
+	StatusReasonServerTimeout:         "server.timeout",
+	StatusReasonTimeout:               "server.gateway_timeout",
+	StatusReasonInternalError:         "server.internal_error",
+	StatusReasonServiceUnavailable:    "server.unavailable",
+}
+
+// MachineCode returns a short, stable code for the reason, such as
+// "resource.not_found", that clients can switch on instead of parsing
+// messages. Reasons that are not built-in, such as those registered with
+// RegisterReason, are converted to snake case with a "custom." prefix, e.g.
+// "custom.billing_required" for "BillingRequired".
+func MachineCode(reason StatusReason) string {
+	if code, ok := machineCodes[reason]; ok {
+		return code
+	}
+	runes := []rune(string(reason))
+	var b strings.Builder
+	b.WriteString("custom.")
+	for i, r := range runes {
+		// start a new word at an upper case letter that follows a lower case
+		// letter, or that ends an acronym, e.g. "HTTPError" -> "http_error"
+		if i > 0 && unicode.IsUpper(r) &&
+			(!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
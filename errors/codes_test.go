@@ -0,0 +1,199 @@
+package errors
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHTTPCodeForReason(t *testing.T) {
+	testCases := []struct {
+		reason       StatusReason
+		expectedCode int
+	}{
+		{StatusReasonUnknown, http.StatusInternalServerError},
+		{StatusReasonUnauthorized, http.StatusUnauthorized},
+		{StatusReasonForbidden, http.StatusForbidden},
+		{StatusReasonNotFound, http.StatusNotFound},
+		{StatusReasonAlreadyExists, http.StatusConflict},
+		{StatusReasonConflict, http.StatusConflict},
+		{StatusReasonPreconditionFailed, http.StatusPreconditionFailed},
+		{StatusReasonInvalid, http.StatusUnprocessableEntity},
+		{StatusReasonServerTimeout, http.StatusInternalServerError},
+		{StatusReasonTimeout, http.StatusGatewayTimeout},
+		{StatusReasonRequestTimeout, http.StatusRequestTimeout},
+		{StatusReasonClientClosedRequest, StatusClientClosedRequest},
+		{StatusReasonTooManyRequests, http.StatusTooManyRequests},
+		{StatusReasonBadRequest, http.StatusBadRequest},
+		{StatusReasonMethodNotAllowed, http.StatusMethodNotAllowed},
+		{StatusReasonNotAcceptable, http.StatusNotAcceptable},
+		{StatusReasonRequestEntityTooLarge, http.StatusRequestEntityTooLarge},
+		{StatusReasonUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{StatusReasonPaymentRequired, http.StatusPaymentRequired},
+		{StatusReasonLocked, http.StatusLocked},
+		{StatusReasonFailedDependency, http.StatusFailedDependency},
+		{StatusReasonInternalError, http.StatusInternalServerError},
+		{StatusReasonServiceUnavailable, http.StatusServiceUnavailable},
+		{StatusReason("SomethingElse"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		if code := HTTPCodeForReason(tc.reason); code != tc.expectedCode {
+			t.Errorf("%q: expected code %d, got %d", tc.reason, tc.expectedCode, code)
+		}
+	}
+	for reason, code := range reasonCodes {
+		if code < 400 || code > 599 || (len(http.StatusText(code)) == 0 && code != StatusClientClosedRequest) {
+			t.Errorf("%q: unexpected code %d", reason, code)
+		}
+	}
+}
+
+func TestReasonForHTTPCode(t *testing.T) {
+	testCases := []struct {
+		name           string
+		code           int
+		verb           string
+		expectedReason StatusReason
+	}{
+		{"conflict on POST", http.StatusConflict, "POST", StatusReasonAlreadyExists},
+		{"conflict on PUT", http.StatusConflict, "PUT", StatusReasonConflict},
+		{"conflict without verb", http.StatusConflict, "", StatusReasonConflict},
+		{"not found", http.StatusNotFound, "GET", StatusReasonNotFound},
+		{"precondition failed", http.StatusPreconditionFailed, "PUT", StatusReasonPreconditionFailed},
+		{"bad request", http.StatusBadRequest, "POST", StatusReasonBadRequest},
+		{"unauthorized", http.StatusUnauthorized, "GET", StatusReasonUnauthorized},
+		{"forbidden", http.StatusForbidden, "GET", StatusReasonForbidden},
+		{"not acceptable", http.StatusNotAcceptable, "GET", StatusReasonNotAcceptable},
+		{"unsupported media type", http.StatusUnsupportedMediaType, "POST", StatusReasonUnsupportedMediaType},
+		{"method not allowed", http.StatusMethodNotAllowed, "DELETE", StatusReasonMethodNotAllowed},
+		{"unprocessable entity", http.StatusUnprocessableEntity, "POST", StatusReasonInvalid},
+		{"service unavailable", http.StatusServiceUnavailable, "GET", StatusReasonServiceUnavailable},
+		{"request timeout", http.StatusRequestTimeout, "GET", StatusReasonRequestTimeout},
+		{"gateway timeout", http.StatusGatewayTimeout, "GET", StatusReasonTimeout},
+		{"too many requests", http.StatusTooManyRequests, "GET", StatusReasonTooManyRequests},
+		{"client closed request", StatusClientClosedRequest, "GET", StatusReasonClientClosedRequest},
+		{"payment required", http.StatusPaymentRequired, "GET", StatusReasonPaymentRequired},
+		{"locked", http.StatusLocked, "GET", StatusReasonLocked},
+		{"failed dependency", http.StatusFailedDependency, "GET", StatusReasonFailedDependency},
+		{"internal server error", http.StatusInternalServerError, "GET", StatusReasonInternalError},
+		{"unrecognized server error", http.StatusBadGateway, "GET", StatusReasonInternalError},
+		{"unrecognized client error", http.StatusTeapot, "GET", StatusReasonUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if reason := ReasonForHTTPCode(tc.code, tc.verb); reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+			if reason := ReasonForError(NewGenericServerResponse(tc.code, tc.verb, "", "", 0, false)); reason != tc.expectedReason {
+				t.Errorf("NewGenericServerResponse: expected reason %q, got %q", tc.expectedReason, reason)
+			}
+		})
+	}
+}
+
+func TestRegisterReason(t *testing.T) {
+	const (
+		reasonBillingRequired StatusReason = "BillingRequired"
+		reasonLeaseHeld       StatusReason = "LeaseHeld"
+		reasonBackendDown     StatusReason = "BackendDown"
+	)
+	defer func() {
+		customReasonsLock.Lock()
+		defer customReasonsLock.Unlock()
+		delete(customReasons, reasonBillingRequired)
+		delete(customReasons, reasonLeaseHeld)
+		delete(customReasons, reasonBackendDown)
+	}()
+	RegisterReason(reasonBillingRequired, http.StatusPaymentRequired, false)
+	RegisterReason(reasonLeaseHeld, http.StatusConflict, true)
+	RegisterReason(reasonBackendDown, http.StatusBadGateway, false)
+
+	testCases := []struct {
+		reason          StatusReason
+		expectedCode    int
+		expectRetryable bool
+	}{
+		{reasonBillingRequired, http.StatusPaymentRequired, false},
+		{reasonLeaseHeld, http.StatusConflict, true},
+		{reasonBackendDown, http.StatusBadGateway, false},
+	}
+	for _, tc := range testCases {
+		if code := HTTPCodeForReason(tc.reason); code != tc.expectedCode {
+			t.Errorf("%q: expected code %d, got %d", tc.reason, tc.expectedCode, code)
+		}
+		err := &StatusError{ErrStatus: Status{Status: StatusFailure, Reason: tc.reason, Message: "custom"}}
+		status := ErrorToAPIStatus(err)
+		if status.Code != int32(tc.expectedCode) {
+			t.Errorf("%q: expected ErrorToAPIStatus to use code %d, got %d", tc.reason, tc.expectedCode, status.Code)
+		}
+		if err := ValidateStatus(status); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.reason, err)
+		}
+		if IsRetryable(&StatusError{ErrStatus: *status}) != tc.expectRetryable {
+			t.Errorf("%q: expected retryable to be %v", tc.reason, tc.expectRetryable)
+		}
+	}
+	if err := ValidateStatus(&Status{Status: StatusFailure, Reason: reasonBillingRequired, Code: http.StatusBadRequest}); err == nil {
+		t.Errorf("expected a code mismatch for a registered reason to be invalid")
+	}
+
+	for _, reason := range []StatusReason{StatusReasonNotFound, StatusReasonUnknown} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: expected registering a built-in reason to panic", reason)
+				}
+			}()
+			RegisterReason(reason, http.StatusTeapot, false)
+		}()
+	}
+	if code := HTTPCodeForReason(StatusReasonNotFound); code != http.StatusNotFound {
+		t.Errorf("expected built-in reasons to be immutable, got %d", code)
+	}
+}
+
+func TestMachineCode(t *testing.T) {
+	testCases := []struct {
+		reason   StatusReason
+		expected string
+	}{
+		{StatusReasonUnknown, "unknown"},
+		{StatusReasonUnauthorized, "auth.unauthorized"},
+		{StatusReasonForbidden, "auth.forbidden"},
+		{StatusReasonNotFound, "resource.not_found"},
+		{StatusReasonAlreadyExists, "resource.already_exists"},
+		{StatusReasonConflict, "resource.conflict"},
+		{StatusReasonPreconditionFailed, "resource.precondition_failed"},
+		{StatusReasonLocked, "resource.locked"},
+		{StatusReasonFailedDependency, "resource.failed_dependency"},
+		{StatusReasonInvalid, "request.invalid"},
+		{StatusReasonBadRequest, "request.bad_request"},
+		{StatusReasonMethodNotAllowed, "request.method_not_allowed"},
+		{StatusReasonNotAcceptable, "request.not_acceptable"},
+		{StatusReasonRequestEntityTooLarge, "request.entity_too_large"},
+		{StatusReasonUnsupportedMediaType, "request.unsupported_media_type"},
+		{StatusReasonRequestTimeout, "request.timeout"},
+		{StatusReasonClientClosedRequest, "request.client_closed"},
+		{StatusReasonTooManyRequests, "quota.too_many_requests"},
+		{StatusReasonPaymentRequired, "quota.payment_required"},
+		{StatusReasonServerTimeout, "server.timeout"},
+		{StatusReasonTimeout, "server.gateway_timeout"},
+		{StatusReasonInternalError, "server.internal_error"},
+		{StatusReasonServiceUnavailable, "server.unavailable"},
+		{StatusReason("BillingRequired"), "custom.billing_required"},
+		{StatusReason("UpstreamHTTPError"), "custom.upstream_http_error"},
+		{StatusReason("Quota2Exceeded"), "custom.quota2_exceeded"},
+	}
+
+	for _, tc := range testCases {
+		if code := MachineCode(tc.reason); code != tc.expected {
+			t.Errorf("%q: expected machine code %q, got %q", tc.reason, tc.expected, code)
+		}
+	}
+	for reason := range reasonCodes {
+		if _, ok := machineCodes[reason]; !ok {
+			t.Errorf("%q: missing machine code", reason)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StatusEqual returns true if the statuses are semantically equal. Unlike
+// reflect.DeepEqual, nil and empty details, and nil and empty causes, are
+// considered equal.
+func StatusEqual(a, b *Status) bool {
+	return len(statusDiffs(a, b)) == 0
+}
+
+// StatusDiff returns a human-readable description of the differences between
+// the statuses, one per line, or an empty string if they are StatusEqual.
+func StatusDiff(a, b *Status) string {
+	return strings.Join(statusDiffs(a, b), "\n")
+}
+
+// statusDiffs returns the differences between the statuses.
+func statusDiffs(a, b *Status) []string {
+	if a == nil || b == nil {
+		if a == b {
+			return nil
+		}
+		return []string{fmt.Sprintf("Status: %v != %v", a, b)}
+	}
+	var diffs []string
+	diffString := func(name, a, b string) {
+		if a != b {
+			diffs = append(diffs, fmt.Sprintf("%s: %q != %q", name, a, b))
+		}
+	}
+	diffInt := func(name string, a, b int32) {
+		if a != b {
+			diffs = append(diffs, fmt.Sprintf("%s: %d != %d", name, a, b))
+		}
+	}
+	diffString("APIVersion", a.APIVersion, b.APIVersion)
+	diffString("Kind", a.Kind, b.Kind)
+	diffString("Status", a.Status, b.Status)
+	diffString("Message", a.Message, b.Message)
+	diffString("Reason", string(a.Reason), string(b.Reason))
+	diffInt("Code", a.Code, b.Code)
+
+	aDetails, bDetails := detailsOrEmpty(a.Details), detailsOrEmpty(b.Details)
+	diffString("Details.Name", aDetails.Name, bDetails.Name)
+	diffString("Details.UID", aDetails.UID, bDetails.UID)
+	diffInt("Details.RetryAfterSeconds", aDetails.RetryAfterSeconds, bDetails.RetryAfterSeconds)
+	for i := 0; i < len(aDetails.Causes) || i < len(bDetails.Causes); i++ {
+		name := fmt.Sprintf("Details.Causes[%d]", i)
+		switch {
+		case i >= len(aDetails.Causes):
+			diffs = append(diffs, fmt.Sprintf("%s: missing != %+v", name, bDetails.Causes[i]))
+		case i >= len(bDetails.Causes):
+			diffs = append(diffs, fmt.Sprintf("%s: %+v != missing", name, aDetails.Causes[i]))
+		default:
+			aCause, bCause := aDetails.Causes[i], bDetails.Causes[i]
+			diffString(name+".Type", string(aCause.Type), string(bCause.Type))
+			diffString(name+".Message", aCause.Message, bCause.Message)
+			diffString(name+".Field", aCause.Field, bCause.Field)
+			diffString(name+".Value", aCause.Value, bCause.Value)
+			diffString(name+".ErrorCode", aCause.ErrorCode, bCause.ErrorCode)
+		}
+	}
+	return diffs
+}
+
+// detailsOrEmpty returns the details, or empty details if they are nil.
+func detailsOrEmpty(details *StatusDetails) *StatusDetails {
+	if details == nil {
+		return &StatusDetails{}
+	}
+	return details
+}
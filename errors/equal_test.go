@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStatusEqual(t *testing.T) {
+	notFound := ErrorToAPIStatus(NewNotFound("tests", ""))
+
+	testCases := []struct {
+		name          string
+		a             *Status
+		b             *Status
+		expectEqual   bool
+		expectedDiffs []string
+	}{
+		{
+			name:        "Equal",
+			a:           notFound,
+			b:           ErrorToAPIStatus(NewNotFound("tests", "")),
+			expectEqual: true,
+		},
+		{
+			name:        "Both nil",
+			expectEqual: true,
+		},
+		{
+			name:          "One nil",
+			a:             notFound,
+			expectedDiffs: []string{"Status: "},
+		},
+		{
+			name:        "Nil and empty causes",
+			a:           &Status{Status: StatusFailure, Details: &StatusDetails{Name: "tests", Causes: nil}},
+			b:           &Status{Status: StatusFailure, Details: &StatusDetails{Name: "tests", Causes: []StatusCause{}}},
+			expectEqual: true,
+		},
+		{
+			name:        "Nil and empty details",
+			a:           &Status{Status: StatusFailure},
+			b:           &Status{Status: StatusFailure, Details: &StatusDetails{}},
+			expectEqual: true,
+		},
+		{
+			name: "Different",
+			a:    notFound,
+			b:    ErrorToAPIStatus(NewConflict("tests", errors.New("conflict"))),
+			expectedDiffs: []string{
+				`Message: "tests not found" != "Operation cannot be fulfilled on tests: conflict"`,
+				`Reason: "NotFound" != "Conflict"`,
+				`Code: 404 != 409`,
+			},
+		},
+		{
+			name: "Different causes",
+			a: &Status{Details: &StatusDetails{Causes: []StatusCause{
+				{Type: CauseTypeFieldValueInvalid, Field: "a"},
+			}}},
+			b: &Status{Details: &StatusDetails{Causes: []StatusCause{
+				{Type: CauseTypeFieldValueInvalid, Field: "b"},
+				{Type: CauseTypeFieldValueRequired, Field: "c"},
+			}}},
+			expectedDiffs: []string{
+				`Details.Causes[0].Field: "a" != "b"`,
+				`Details.Causes[1]: missing != `,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if StatusEqual(tc.a, tc.b) != tc.expectEqual {
+				t.Errorf("expected equal to be %v", tc.expectEqual)
+			}
+			diff := StatusDiff(tc.a, tc.b)
+			if tc.expectEqual && diff != "" {
+				t.Errorf("expected no diff, got %s", diff)
+			}
+			lines := strings.Split(diff, "\n")
+			if !tc.expectEqual && len(lines) != len(tc.expectedDiffs) {
+				t.Fatalf("expected %d differences, got %s", len(tc.expectedDiffs), diff)
+			}
+			for i, expected := range tc.expectedDiffs {
+				if !strings.HasPrefix(lines[i], expected) {
+					t.Errorf("expected difference %q, got %q", expected, lines[i])
+				}
+			}
+		})
+	}
+}
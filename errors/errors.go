@@ -1,20 +1,29 @@
 package errors
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"mime"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // StatusError is an error intended for consumption by a REST API server; it can also be
 // reconstructed by clients from a REST response. Public to allow easy type switches.
 type StatusError struct {
 	ErrStatus Status
+	// cause is the error that caused this one, if any. It is only available
+	// through Unwrap and is not part of the status sent to clients.
+	cause error
 }
 
 // APIStatus is exposed by errors that can be converted to an api.Status object
@@ -23,19 +32,88 @@ type APIStatus interface {
 	Status() Status
 }
 
+// APIError is exposed by errors that describe an API failure through a stable set of accessors
+// rather than a Status object.
+type APIError interface {
+	error
+	// Code returns the HTTP status code of the error.
+	Code() int32
+	// Reason returns the machine-readable reason of the error.
+	Reason() StatusReason
+	// Message returns the human-readable message of the error.
+	Message() string
+	// Retryable returns true if the request may succeed if it is retried.
+	Retryable() bool
+}
+
 var _ error = &StatusError{}
+var _ APIError = &StatusError{}
+
+// VerboseErrors makes StatusError.Error return the compact summary of
+// Status.String, including the code and reason, instead of only the message.
+// It is disabled by default since callers may compare error messages.
+var VerboseErrors = false
 
 // Error implements the Error interface.
 func (e *StatusError) Error() string {
+	if VerboseErrors {
+		return e.ErrStatus.String()
+	}
 	return e.ErrStatus.Message
 }
 
+// String returns a compact one-line summary of s for logging, e.g.
+// Status(404 NotFound: "tests not found").
+func (s Status) String() string {
+	if len(s.Reason) == 0 {
+		return fmt.Sprintf("Status(%d: %q)", s.Code, s.Message)
+	}
+	return fmt.Sprintf("Status(%d %s: %q)", s.Code, s.Reason, s.Message)
+}
+
+// Unwrap returns the error that caused e, or nil if there is none, so that
+// errors.Is and errors.As can traverse to it.
+func (e *StatusError) Unwrap() error {
+	return e.cause
+}
+
 // Status allows access to e's status without having to know the detailed workings
 // of StatusError.
 func (e *StatusError) Status() Status {
 	return e.ErrStatus
 }
 
+// Code returns the HTTP status code of e.
+func (e *StatusError) Code() int32 {
+	return e.ErrStatus.Code
+}
+
+// Reason returns the reason of e.
+func (e *StatusError) Reason() StatusReason {
+	return e.ErrStatus.Reason
+}
+
+// Message returns the message of e.
+func (e *StatusError) Message() string {
+	return e.ErrStatus.Message
+}
+
+// Retryable returns true if e is retryable according to IsRetryable.
+func (e *StatusError) Retryable() bool {
+	return IsRetryable(e)
+}
+
+// AsAPIError returns the first error in the chain of err that implements APIError and true, or
+// nil and false if there is none.
+// It supports wrapped errors.
+func AsAPIError(err error) (APIError, bool) {
+	apiErr := APIError(nil)
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
 // DebugError reports extended info about the error to debug output.
 func (e *StatusError) DebugError() (string, []interface{}) {
 	if out, err := json.MarshalIndent(e.ErrStatus, "", "  "); err == nil {
@@ -44,6 +122,83 @@ func (e *StatusError) DebugError() (string, []interface{}) {
 	return "server response object: %#v", []interface{}{e.ErrStatus}
 }
 
+// DeepCopy returns a copy of e that shares no memory with the original, so that
+// the copy can be modified without affecting e. Returns nil when e is nil.
+func (e *StatusError) DeepCopy() *StatusError {
+	if e == nil {
+		return nil
+	}
+	out := *e
+	if e.ErrStatus.Details != nil {
+		details := *e.ErrStatus.Details
+		if details.Causes != nil {
+			details.Causes = make([]StatusCause, len(e.ErrStatus.Details.Causes))
+			copy(details.Causes, e.ErrStatus.Details.Causes)
+		}
+		out.ErrStatus.Details = &details
+	}
+	return &out
+}
+
+// WithMessage returns a copy of e with its message replaced by msg. The
+// original error is not modified. Returns nil when e is nil.
+func (e *StatusError) WithMessage(msg string) *StatusError {
+	out := e.DeepCopy()
+	if out == nil {
+		return nil
+	}
+	out.ErrStatus.Message = msg
+	return out
+}
+
+// WithContext returns a copy of err with the formatted context prepended to
+// its message, e.g. "while syncing node X: <original message>". The reason,
+// code and details are preserved, and unlike wrapping err with fmt.Errorf the
+// result is still a *StatusError. The original error is not modified. Returns
+// nil when err is nil.
+func WithContext(err *StatusError, format string, args ...interface{}) *StatusError {
+	if err == nil {
+		return nil
+	}
+	return err.WithMessage(fmt.Sprintf(format, args...) + ": " + err.ErrStatus.Message)
+}
+
+// WithDocumentationURL returns a copy of e that links to the documentation at
+// url with a DocumentationURL cause, replacing any existing link. The original
+// error is not modified. Returns nil when e is nil.
+func (e *StatusError) WithDocumentationURL(url string) *StatusError {
+	out := e.DeepCopy()
+	if out == nil {
+		return nil
+	}
+	if out.ErrStatus.Details == nil {
+		out.ErrStatus.Details = &StatusDetails{}
+	}
+	causes := out.ErrStatus.Details.Causes[:0]
+	for _, cause := range out.ErrStatus.Details.Causes {
+		if cause.Type != CauseTypeDocumentationURL {
+			causes = append(causes, cause)
+		}
+	}
+	out.ErrStatus.Details.Causes = append(causes, StatusCause{
+		Type:    CauseTypeDocumentationURL,
+		Message: "see " + url + " for more information",
+		Value:   url,
+	})
+	return out
+}
+
+// DocumentationURLForError returns the documentation URL attached to the
+// provided error with WithDocumentationURL, if any.
+// It supports wrapped errors.
+func DocumentationURLForError(err error) (string, bool) {
+	causes := GetStatusCauses(err, CauseTypeDocumentationURL)
+	if len(causes) == 0 {
+		return "", false
+	}
+	return causes[0].Value, true
+}
+
 // HasStatusCause returns true if the provided error has a details cause
 // with the provided type name.
 func HasStatusCause(err error, name CauseType) bool {
@@ -66,6 +221,79 @@ func GetStatusCause(err error, name CauseType) (StatusCause, bool) {
 	return StatusCause{}, false
 }
 
+// FieldErrors returns the messages of the field causes of the provided error
+// keyed by field, e.g. to present the errors of NewInvalid in a form. It is the
+// inverse of NewInvalidFromMap. Causes without a field are skipped, and the
+// messages of multiple causes for the same field are joined with "; ". Returns
+// nil if the error has no field causes.
+// It supports wrapped errors.
+func FieldErrors(err error) map[string]string {
+	details, ok := DetailsFromError(err)
+	if !ok {
+		return nil
+	}
+	var out map[string]string
+	for _, cause := range details.Causes {
+		if len(cause.Field) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		if existing, ok := out[cause.Field]; ok {
+			out[cause.Field] = existing + "; " + cause.Message
+		} else {
+			out[cause.Field] = cause.Message
+		}
+	}
+	return out
+}
+
+// HasAnyStatusCause returns true if the provided error has a details cause with any of the
+// provided type names.
+// It supports wrapped errors.
+func HasAnyStatusCause(err error, names ...CauseType) bool {
+	details, ok := DetailsFromError(err)
+	if !ok {
+		return false
+	}
+	for _, cause := range details.Causes {
+		for _, name := range names {
+			if cause.Type == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetStatusCauses returns every cause with the provided type name from the provided error, or
+// nil if the error has no such causes.
+// It supports wrapped errors.
+func GetStatusCauses(err error, name CauseType) []StatusCause {
+	details, ok := DetailsFromError(err)
+	if !ok {
+		return nil
+	}
+	var causes []StatusCause
+	for _, cause := range details.Causes {
+		if cause.Type == name {
+			causes = append(causes, cause)
+		}
+	}
+	return causes
+}
+
+// DetailsFromError returns the details of the provided error and true if the error is of the type
+// APIStatus and has details. Otherwise it returns false.
+// It supports wrapped errors.
+func DetailsFromError(err error) (*StatusDetails, bool) {
+	if status := APIStatus(nil); errors.As(err, &status) && status.Status().Details != nil {
+		return status.Status().Details, true
+	}
+	return nil, false
+}
+
 // UnexpectedObjectError can be returned by FromObject if it's passed a non-status object.
 type UnexpectedObjectError struct {
 	Object interface{}
@@ -90,14 +318,16 @@ func FromObject(obj interface{}) error {
 func NewNotFound(name string, uid string) *StatusError {
 	var message string
 	if len(uid) > 0 {
-		message = fmt.Sprintf("%s (%s) not found", name, uid)
+		message = localize(StatusReasonNotFound, "%s (%s) not found", name, uid)
 	} else {
-		message = fmt.Sprintf("%s not found", name)
+		message = localize(StatusReasonNotFound, "%s not found", name)
 	}
-	return &StatusError{Status{
-		Status: StatusFailure,
-		Code:   http.StatusNotFound,
-		Reason: StatusReasonNotFound,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonNotFound)),
+		Reason:     StatusReasonNotFound,
 		Details: &StatusDetails{
 			Name: name,
 			UID:  uid,
@@ -110,14 +340,16 @@ func NewNotFound(name string, uid string) *StatusError {
 func NewAlreadyExists(name string, uid string) *StatusError {
 	var message string
 	if len(uid) > 0 {
-		message = fmt.Sprintf("%s (%s) not found", name, uid)
+		message = localize(StatusReasonAlreadyExists, "%s (%s) not found", name, uid)
 	} else {
-		message = fmt.Sprintf("%s not found", name)
+		message = localize(StatusReasonAlreadyExists, "%s not found", name)
 	}
-	return &StatusError{Status{
-		Status: StatusFailure,
-		Code:   http.StatusConflict,
-		Reason: StatusReasonAlreadyExists,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonAlreadyExists)),
+		Reason:     StatusReasonAlreadyExists,
 		Details: &StatusDetails{
 			Name: name,
 			UID:  uid,
@@ -129,42 +361,84 @@ func NewAlreadyExists(name string, uid string) *StatusError {
 // NewUnauthorized returns an error indicating the client is not authorized to perform the requested
 // action.
 func NewUnauthorized(reason string) *StatusError {
-	message := reason
-	if len(message) == 0 {
-		message = "not authorized"
-	}
-	return &StatusError{Status{
-		Status:  StatusFailure,
-		Code:    http.StatusUnauthorized,
-		Reason:  StatusReasonUnauthorized,
-		Message: message,
+	message := localize(StatusReasonUnauthorized, "%s", reason)
+	if len(reason) == 0 {
+		message = localize(StatusReasonUnauthorized, "not authorized")
+	}
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonUnauthorized)),
+		Reason:     StatusReasonUnauthorized,
+		Message:    message,
 	}}
 }
 
-// NewForbidden returns an error indicating the requested action was forbidden
+// NewForbidden returns an error indicating the requested action was forbidden.
+// The error wraps err.
 func NewForbidden(name string, err error) *StatusError {
-	message := fmt.Sprintf("forbidden: %v", err)
-	return &StatusError{Status{
-		Status: StatusFailure,
-		Code:   http.StatusForbidden,
-		Reason: StatusReasonForbidden,
+	message := localize(StatusReasonForbidden, "forbidden: %v", err)
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonForbidden)),
+		Reason:     StatusReasonForbidden,
 		Details: &StatusDetails{
 			Name: name,
 		},
 		Message: message,
-	}}
+	}, cause: err}
+}
+
+// NewForbiddenWithCause returns an error indicating the requested action was
+// forbidden for the provided reason. Unlike NewForbidden, the cause is not
+// included in the message and can only be reached with errors.Is and
+// errors.As.
+func NewForbiddenWithCause(name string, reason string, cause error) *StatusError {
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonForbidden)),
+		Reason:     StatusReasonForbidden,
+		Details: &StatusDetails{
+			Name: name,
+		},
+		Message: localize(StatusReasonForbidden, "forbidden: %s", reason),
+	}, cause: cause}
 }
 
 // NewConflict returns an error indicating the item can't be updated as provided.
+// The error wraps err.
 func NewConflict(name string, err error) *StatusError {
-	return &StatusError{Status{
-		Status: StatusFailure,
-		Code:   http.StatusConflict,
-		Reason: StatusReasonConflict,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonConflict)),
+		Reason:     StatusReasonConflict,
 		Details: &StatusDetails{
 			Name: name,
 		},
-		Message: fmt.Sprintf("Operation cannot be fulfilled on %s: %v", name, err),
+		Message: localize(StatusReasonConflict, "Operation cannot be fulfilled on %s: %v", name, err),
+	}, cause: err}
+}
+
+// NewPreconditionFailed returns an error indicating that a precondition of the operation on the
+// item, such as its expected version, was not met.
+func NewPreconditionFailed(name, message string) *StatusError {
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonPreconditionFailed)),
+		Reason:     StatusReasonPreconditionFailed,
+		Details: &StatusDetails{
+			Name: name,
+		},
+		Message: localize(StatusReasonPreconditionFailed, "Precondition failed for %s: %s", name, message),
 	}}
 }
 
@@ -177,27 +451,160 @@ func NewInvalid(name string, errs field.ErrorList) *StatusError {
 			Type:    CauseType(err.Type),
 			Message: err.ErrorBody(),
 			Field:   err.Field,
+			Value:   causeValue(err.BadValue),
+		})
+	}
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonInvalid)),
+		Reason:     StatusReasonInvalid,
+		Details: &StatusDetails{
+			Name:   name,
+			Causes: causes,
+		},
+		Message: localize(StatusReasonInvalid, "%s is invalid: %v", name, errs.ToAggregate()),
+	}}
+}
+
+// NewInvalidFromMap returns an error indicating the item is invalid and cannot be processed, built
+// from a map of field names to messages. The causes are sorted by field so that the output is
+// stable. An empty map results in an error without causes and the message "<name> is invalid".
+func NewInvalidFromMap(name string, fieldErrors map[string]string) *StatusError {
+	fields := make([]string, 0, len(fieldErrors))
+	for f := range fieldErrors {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	causes := make([]StatusCause, 0, len(fields))
+	for _, f := range fields {
+		causes = append(causes, StatusCause{
+			Type:    CauseTypeFieldValueInvalid,
+			Message: fieldErrors[f],
+			Field:   f,
 		})
 	}
-	return &StatusError{Status{
-		Status: StatusFailure,
-		Code:   http.StatusUnprocessableEntity,
-		Reason: StatusReasonInvalid,
+	return NewInvalidFromCauses(name, causes)
+}
+
+// NewInvalidFromCauses returns an error indicating the item is invalid and cannot be processed,
+// with the provided causes as its details. It allows callers that do not use the field package
+// to build validation errors from causes directly. Without causes, the message is
+// "<name> is invalid".
+func NewInvalidFromCauses(name string, causes []StatusCause) *StatusError {
+	message := localize(StatusReasonInvalid, "%s is invalid", name)
+	if len(causes) > 0 {
+		message = localize(StatusReasonInvalid, "%s is invalid: %s", name, summarizeCauses(causes))
+	}
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonInvalid)),
+		Reason:     StatusReasonInvalid,
 		Details: &StatusDetails{
 			Name:   name,
 			Causes: causes,
 		},
-		Message: fmt.Sprintf("%s is invalid: %v", name, errs.ToAggregate()),
+		Message: message,
+	}}
+}
+
+// summarizeCauses renders the causes in the same format as an aggregated field.ErrorList.
+func summarizeCauses(causes []StatusCause) string {
+	messages := make([]string, 0, len(causes))
+	for _, cause := range causes {
+		if len(cause.Field) > 0 {
+			messages = append(messages, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+		} else {
+			messages = append(messages, cause.Message)
+		}
+	}
+	if len(messages) == 1 {
+		return messages[0]
+	}
+	return "[" + strings.Join(messages, ", ") + "]"
+}
+
+// CauseWithValue returns a cause of the provided type for the field that also carries the value
+// that caused the error.
+func CauseWithValue(causeType CauseType, field, message, value string) StatusCause {
+	return StatusCause{
+		Type:    causeType,
+		Message: message,
+		Field:   field,
+		Value:   value,
+	}
+}
+
+// causeValue renders the value of a field error as a string, nil values are rendered as an
+// empty string.
+func causeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// NewAggregate returns an error that combines the provided errors into one. The causes of every
+// error are collected into a single list (errors without causes contribute their message as a
+// cause), and the code and reason are taken from the error with the highest HTTP status code.
+// Nil errors are ignored, and nil is returned if there are no errors to aggregate.
+func NewAggregate(errs []error) *StatusError {
+	var (
+		causes   []StatusCause
+		messages []string
+		worst    *Status
+	)
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		status := ErrorToAPIStatus(err)
+		if status.Details != nil && len(status.Details.Causes) > 0 {
+			causes = append(causes, status.Details.Causes...)
+		} else {
+			causes = append(causes, StatusCause{Message: status.Message})
+		}
+		messages = append(messages, status.Message)
+		if worst == nil || status.Code > worst.Code {
+			worst = status
+		}
+	}
+	if worst == nil {
+		return nil
+	}
+	message := messages[0]
+	if len(messages) > 1 {
+		message = "[" + strings.Join(messages, ", ") + "]"
+	}
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       worst.Code,
+		Reason:     worst.Reason,
+		Details: &StatusDetails{
+			Causes: causes,
+		},
+		Message: message,
 	}}
 }
 
 // NewBadRequest creates an error that indicates that the request is invalid and can not be processed.
 func NewBadRequest(reason string) *StatusError {
-	return &StatusError{Status{
-		Status:  StatusFailure,
-		Code:    http.StatusBadRequest,
-		Reason:  StatusReasonBadRequest,
-		Message: reason,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonBadRequest)),
+		Reason:     StatusReasonBadRequest,
+		Message:    localize(StatusReasonBadRequest, "%s", reason),
 	}}
 }
 
@@ -205,129 +612,259 @@ func NewBadRequest(reason string) *StatusError {
 // the specified endpoint is not accepting requests. More specific details should be provided
 // if client should know why the failure was limited4.
 func NewTooManyRequests(message string, retryAfterSeconds int) *StatusError {
-	return &StatusError{Status{
-		Status:  StatusFailure,
-		Code:    http.StatusTooManyRequests,
-		Reason:  StatusReasonTooManyRequests,
-		Message: message,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonTooManyRequests)),
+		Reason:     StatusReasonTooManyRequests,
+		Message:    localize(StatusReasonTooManyRequests, "%s", message),
 		Details: &StatusDetails{
 			RetryAfterSeconds: int32(retryAfterSeconds),
 		},
 	}}
 }
 
+// NewTooManyRequestsWithReason creates an error like NewTooManyRequests that also records why the
+// request was limited as a TooManyRequestsReason cause, e.g. TooManyRequestsRateLimited or
+// TooManyRequestsQuotaExceeded, so that clients can tell the two apart.
+func NewTooManyRequestsWithReason(message string, retryAfterSeconds int, subReason string) *StatusError {
+	err := NewTooManyRequests(message, retryAfterSeconds)
+	err.ErrStatus.Details.Causes = []StatusCause{{
+		Type:    CauseTypeTooManyRequestsReason,
+		Message: fmt.Sprintf("too many requests: %s", subReason),
+		Value:   subReason,
+	}}
+	return err
+}
+
 // NewServiceUnavailable creates an error that indicates that the requested service is unavailable.
 func NewServiceUnavailable(reason string) *StatusError {
-	return &StatusError{Status{
-		Status:  StatusFailure,
-		Code:    http.StatusServiceUnavailable,
-		Reason:  StatusReasonServiceUnavailable,
-		Message: reason,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonServiceUnavailable)),
+		Reason:     StatusReasonServiceUnavailable,
+		Message:    localize(StatusReasonServiceUnavailable, "%s", reason),
 	}}
 }
 
 // NewMethodNotSupported returns an error indicating the requested action is not supported on this kind.
 func NewMethodNotSupported(action string) *StatusError {
-	return &StatusError{Status{
-		Status:  StatusFailure,
-		Code:    http.StatusMethodNotAllowed,
-		Reason:  StatusReasonMethodNotAllowed,
-		Message: fmt.Sprintf("%s is not supported on this resource", action),
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonMethodNotAllowed)),
+		Reason:     StatusReasonMethodNotAllowed,
+		Message:    localize(StatusReasonMethodNotAllowed, "%s is not supported on this resource", action),
 	}}
 }
 
 // NewServerTimeout returns an error indicating the requested action could not be completed due to a
 // transient error, and the client should try again.
 func NewServerTimeout(operation string, retryAfterSeconds int) *StatusError {
-	return &StatusError{Status{
-		Status: StatusFailure,
-		Code:   http.StatusInternalServerError,
-		Reason: StatusReasonServerTimeout,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonServerTimeout)),
+		Reason:     StatusReasonServerTimeout,
 		Details: &StatusDetails{
 			Name:              operation,
 			RetryAfterSeconds: int32(retryAfterSeconds),
 		},
-		Message: fmt.Sprintf("The %s operation could not be completed at this time, please try again.", operation),
+		Message: localize(StatusReasonServerTimeout, "The %s operation could not be completed at this time, please try again.", operation),
 	}}
 }
 
 // NewInternalError returns an error indicating the item is invalid and cannot be processed.
+// The error wraps err.
 func NewInternalError(err error) *StatusError {
-	return &StatusError{Status{
-		Status: StatusFailure,
-		Code:   http.StatusInternalServerError,
-		Reason: StatusReasonInternalError,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonInternalError)),
+		Reason:     StatusReasonInternalError,
 		Details: &StatusDetails{
 			Causes: []StatusCause{{Message: err.Error()}},
 		},
-		Message: fmt.Sprintf("Internal error occurred: %v", err),
-	}}
+		Message: localize(StatusReasonInternalError, "Internal error occurred: %v", err),
+	}, cause: err}
 }
 
 // NewTimeoutError returns an error indicating that a timeout occurred before the request
 // could be completed.  Clients may retry, but the operation may still complete.
 func NewTimeoutError(message string, retryAfterSeconds int) *StatusError {
-	return &StatusError{Status{
-		Status:  StatusFailure,
-		Code:    http.StatusGatewayTimeout,
-		Reason:  StatusReasonTimeout,
-		Message: fmt.Sprintf("Timeout: %s", message),
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonTimeout)),
+		Reason:     StatusReasonTimeout,
+		Message:    localize(StatusReasonTimeout, "Timeout: %s", message),
 		Details: &StatusDetails{
 			RetryAfterSeconds: int32(retryAfterSeconds),
 		},
 	}}
 }
 
+// NewRequestTimeout returns an error indicating that the server did not receive the complete
+// request in time, for example because an upload was too slow. Clients may retry after the
+// suggested number of seconds.
+func NewRequestTimeout(message string, retryAfterSeconds int) *StatusError {
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonRequestTimeout)),
+		Reason:     StatusReasonRequestTimeout,
+		Message:    localize(StatusReasonRequestTimeout, "Request timeout: %s", message),
+		Details: &StatusDetails{
+			RetryAfterSeconds: int32(retryAfterSeconds),
+		},
+	}}
+}
+
+// NewClientClosedRequest returns an error indicating the client canceled the
+// request before the server finished handling it.
+func NewClientClosedRequest(message string) *StatusError {
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonClientClosedRequest)),
+		Reason:     StatusReasonClientClosedRequest,
+		Message:    localize(StatusReasonClientClosedRequest, "Client closed request: %s", message),
+	}}
+}
+
+// NewPaymentRequired returns an error indicating that the request cannot be completed
+// until a payment is made.
+func NewPaymentRequired(message string) *StatusError {
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonPaymentRequired)),
+		Reason:     StatusReasonPaymentRequired,
+		Message:    localize(StatusReasonPaymentRequired, "Payment required: %s", message),
+	}}
+}
+
+// NewLocked returns an error indicating that the item is locked and cannot be modified.
+func NewLocked(name, message string) *StatusError {
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonLocked)),
+		Reason:     StatusReasonLocked,
+		Details: &StatusDetails{
+			Name: name,
+		},
+		Message: localize(StatusReasonLocked, "%s is locked: %s", name, message),
+	}}
+}
+
+// NewFailedDependency returns an error indicating that the operation on the item
+// failed because an operation it depended on failed.
+func NewFailedDependency(name, message string) *StatusError {
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonFailedDependency)),
+		Reason:     StatusReasonFailedDependency,
+		Details: &StatusDetails{
+			Name: name,
+		},
+		Message: localize(StatusReasonFailedDependency, "Failed dependency for %s: %s", name, message),
+	}}
+}
+
 // NewTooManyRequestsError returns an error indicating that the request was rejected because
 // the server has received too many requests. Client should wait and retry. But if the request
 // is perishable, then the client should not retry the request.
 func NewTooManyRequestsError(message string) *StatusError {
-	return &StatusError{Status{
-		Status:  StatusFailure,
-		Code:    http.StatusTooManyRequests,
-		Reason:  StatusReasonTooManyRequests,
-		Message: fmt.Sprintf("Too many requests: %s", message),
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonTooManyRequests)),
+		Reason:     StatusReasonTooManyRequests,
+		Message:    localize(StatusReasonTooManyRequests, "Too many requests: %s", message),
 	}}
 }
 
 // NewRequestEntityTooLargeError returns an error indicating that the request
 // entity was too large.
 func NewRequestEntityTooLargeError(message string) *StatusError {
-	return &StatusError{Status{
-		Status:  StatusFailure,
-		Code:    http.StatusRequestEntityTooLarge,
-		Reason:  StatusReasonRequestEntityTooLarge,
-		Message: fmt.Sprintf("Request entity too large: %s", message),
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(HTTPCodeForReason(StatusReasonRequestEntityTooLarge)),
+		Reason:     StatusReasonRequestEntityTooLarge,
+		Message:    localize(StatusReasonRequestEntityTooLarge, "Request entity too large: %s", message),
 	}}
 }
 
 // NewGenericServerResponse returns a new error for server responses that are not in a recognizable form.
+// It is a positional shorthand for NewGenericServerResponseWithOptions.
 func NewGenericServerResponse(code int, verb string, name, serverMessage string, retryAfterSeconds int, isUnexpectedResponse bool) *StatusError {
-	reason := StatusReasonUnknown
+	return NewGenericServerResponseWithOptions(GenericServerResponseOptions{
+		Code:                 code,
+		Verb:                 verb,
+		Name:                 name,
+		ServerMessage:        serverMessage,
+		RetryAfterSeconds:    retryAfterSeconds,
+		IsUnexpectedResponse: isUnexpectedResponse,
+	})
+}
+
+// GenericServerResponseOptions describes a server response that is not in a
+// recognizable form.
+type GenericServerResponseOptions struct {
+	// Code is the HTTP status code of the response.
+	Code int
+	// Verb is the HTTP method of the request, used to tell a conflict on
+	// create apart from other conflicts.
+	Verb string
+	// Name is the name of the resource the request was for, if known.
+	Name string
+	// ServerMessage is the message, or body, returned by the server.
+	ServerMessage string
+	// RetryAfterSeconds is the delay suggested by the server, if any.
+	RetryAfterSeconds int
+	// IsUnexpectedResponse records ServerMessage as an unexpected server
+	// response cause.
+	IsUnexpectedResponse bool
+}
+
+// NewGenericServerResponseWithOptions returns a new error for server responses that are not in a
+// recognizable form.
+func NewGenericServerResponseWithOptions(opts GenericServerResponseOptions) *StatusError {
+	code, serverMessage := opts.Code, opts.ServerMessage
+	reason := ReasonForHTTPCode(code, opts.Verb)
 	message := fmt.Sprintf("the server responded with the status code %d but did not return more information", code)
 	switch code {
 	case http.StatusConflict:
-		if verb == "POST" {
-			reason = StatusReasonAlreadyExists
-		} else {
-			reason = StatusReasonConflict
-		}
 		message = "the server reported a conflict"
 	case http.StatusNotFound:
-		reason = StatusReasonNotFound
 		message = "the server could not find the requested resource"
+	case http.StatusPreconditionFailed:
+		message = "the server rejected our request because a precondition was not met"
 	case http.StatusBadRequest:
-		reason = StatusReasonBadRequest
 		message = "the server rejected our request for an unknown reason"
 	case http.StatusUnauthorized:
-		reason = StatusReasonUnauthorized
 		message = "the server has asked for the client to provide credentials"
 	case http.StatusForbidden:
-		reason = StatusReasonForbidden
 		// the server message has details about who is trying to perform what action.  Keep its message.
 		message = serverMessage
 	case http.StatusNotAcceptable:
-		reason = StatusReasonNotAcceptable
 		// the server message has details about what types are acceptable
 		if len(serverMessage) == 0 || serverMessage == "unknown" {
 			message = "the server was unable to respond with a content type that the client supports"
@@ -335,32 +872,35 @@ func NewGenericServerResponse(code int, verb string, name, serverMessage string,
 			message = serverMessage
 		}
 	case http.StatusUnsupportedMediaType:
-		reason = StatusReasonUnsupportedMediaType
 		// the server message has details about what types are acceptable
 		message = serverMessage
 	case http.StatusMethodNotAllowed:
-		reason = StatusReasonMethodNotAllowed
 		message = "the server does not allow this method on the requested resource"
 	case http.StatusUnprocessableEntity:
-		reason = StatusReasonInvalid
 		message = "the server rejected our request due to an error in our request"
 	case http.StatusServiceUnavailable:
-		reason = StatusReasonServiceUnavailable
 		message = "the server is currently unable to handle the request"
+	case http.StatusRequestTimeout:
+		message = "the server timed out waiting for our request to be sent"
 	case http.StatusGatewayTimeout:
-		reason = StatusReasonTimeout
 		message = "the server was unable to return a response in the time allotted, but may still be processing the request"
 	case http.StatusTooManyRequests:
-		reason = StatusReasonTooManyRequests
 		message = "the server has received too many requests and has asked us to try again later"
+	case http.StatusPaymentRequired:
+		message = "the server requires payment before the request can be completed"
+	case http.StatusLocked:
+		message = "the server reported that the requested resource is locked"
+	case http.StatusFailedDependency:
+		message = "the server could not complete the request because a request it depends on failed"
+	case StatusClientClosedRequest:
+		message = "the request was canceled before the server responded"
 	default:
 		if code >= 500 {
-			reason = StatusReasonInternalError
 			message = fmt.Sprintf("an error on the server (%q) has prevented the request from succeeding", serverMessage)
 		}
 	}
 	var causes []StatusCause
-	if isUnexpectedResponse {
+	if opts.IsUnexpectedResponse {
 		causes = []StatusCause{
 			{
 				Type:    CauseTypeUnexpectedServerResponse,
@@ -370,14 +910,16 @@ func NewGenericServerResponse(code int, verb string, name, serverMessage string,
 	} else {
 		causes = nil
 	}
-	return &StatusError{Status{
-		Status: StatusFailure,
-		Code:   int32(code),
-		Reason: reason,
+	return &StatusError{ErrStatus: Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Code:       int32(code),
+		Reason:     reason,
 		Details: &StatusDetails{
-			Name:              name,
+			Name:              opts.Name,
 			Causes:            causes,
-			RetryAfterSeconds: int32(retryAfterSeconds),
+			RetryAfterSeconds: int32(opts.RetryAfterSeconds),
 		},
 		Message: message,
 	}}
@@ -401,6 +943,13 @@ func IsConflict(err error) bool {
 	return ReasonForError(err) == StatusReasonConflict
 }
 
+// IsPreconditionFailed determines if the err is an error which indicates that a precondition of
+// the operation was not met.
+// It supports wrapped errors.
+func IsPreconditionFailed(err error) bool {
+	return ReasonForError(err) == StatusReasonPreconditionFailed
+}
+
 // IsInvalid determines if the err is an error which indicates the provided resource is not valid.
 // It supports wrapped errors.
 func IsInvalid(err error) bool {
@@ -459,6 +1008,41 @@ func IsTimeout(err error) bool {
 	return ReasonForError(err) == StatusReasonTimeout
 }
 
+// IsRequestTimeout determines if err is an error which indicates that the server timed out
+// waiting for the client to send the request.
+// It supports wrapped errors.
+func IsRequestTimeout(err error) bool {
+	return ReasonForError(err) == StatusReasonRequestTimeout
+}
+
+// IsClientClosedRequest determines if err is an error which indicates that the
+// client canceled the request before the server responded.
+// It supports wrapped errors.
+func IsClientClosedRequest(err error) bool {
+	return ReasonForError(err) == StatusReasonClientClosedRequest
+}
+
+// IsPaymentRequired determines if err is an error which indicates that the
+// request cannot be completed until a payment is made.
+// It supports wrapped errors.
+func IsPaymentRequired(err error) bool {
+	return ReasonForError(err) == StatusReasonPaymentRequired
+}
+
+// IsLocked determines if err is an error which indicates that the requested
+// resource is locked.
+// It supports wrapped errors.
+func IsLocked(err error) bool {
+	return ReasonForError(err) == StatusReasonLocked
+}
+
+// IsFailedDependency determines if err is an error which indicates that the
+// request failed because a request it depended on failed.
+// It supports wrapped errors.
+func IsFailedDependency(err error) bool {
+	return ReasonForError(err) == StatusReasonFailedDependency
+}
+
 // IsServerTimeout determines if err is an error which indicates that the request needs to be retried
 // by the client.
 // It supports wrapped errors.
@@ -498,6 +1082,46 @@ func IsRequestEntityTooLargeError(err error) bool {
 	return false
 }
 
+// TooManyRequestsSubReason returns the sub-reason recorded by NewTooManyRequestsWithReason, if any.
+// It supports wrapped errors.
+func TooManyRequestsSubReason(err error) (string, bool) {
+	if ReasonForError(err) != StatusReasonTooManyRequests {
+		return "", false
+	}
+	causes := GetStatusCauses(err, CauseTypeTooManyRequestsReason)
+	if len(causes) == 0 {
+		return "", false
+	}
+	return causes[0].Value, true
+}
+
+// IsRetryable determines if err is an error which indicates that the request may succeed if it
+// is retried, such as timeouts, rate limiting, an unavailable service, or an internal server error.
+// Client errors such as NotFound, Invalid, or Forbidden, and exhausted quotas, are never retryable.
+// It supports wrapped errors.
+func IsRetryable(err error) bool {
+	status := APIStatus(nil)
+	if !errors.As(err, &status) {
+		return false
+	}
+	if subReason, ok := TooManyRequestsSubReason(err); ok && subReason == TooManyRequestsQuotaExceeded {
+		return false
+	}
+	if retryable, ok := registeredRetryable(status.Status().Reason); ok {
+		return retryable
+	}
+	switch status.Status().Reason {
+	case StatusReasonServerTimeout,
+		StatusReasonTimeout,
+		StatusReasonTooManyRequests,
+		StatusReasonServiceUnavailable,
+		StatusReasonInternalError:
+		return true
+	}
+	code := status.Status().Code
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
 // IsUnexpectedServerError returns true if the server response was not in the expected API format,
 // and may be the result of another HTTP actor.
 // It supports wrapped errors.
@@ -519,22 +1143,34 @@ func IsUnexpectedObjectError(err error) bool {
 	return err != nil && errors.As(err, &uoe)
 }
 
+// DefaultDelays are the seconds that SuggestsClientDelay suggests for errors
+// with the given reasons when the error itself does not specify a retry delay,
+// e.g. {StatusReasonTooManyRequests: 1, StatusReasonServiceUnavailable: 5}. It
+// is empty by default and must be set before it is used concurrently.
+var DefaultDelays map[StatusReason]int
+
 // SuggestsClientDelay returns true if this error suggests a client delay as well as the
 // suggested seconds to wait, or false if the error does not imply a wait. It does not
 // address whether the error *should* be retried, since some errors (like a 3xx) may
-// request delay without retry.
+// request delay without retry. When the error does not specify a delay, the delay in
+// DefaultDelays for its reason is suggested, if any.
 // It supports wrapped errors.
 func SuggestsClientDelay(err error) (int, bool) {
-	if t := APIStatus(nil); errors.As(err, &t) && t.Status().Details != nil {
-		switch t.Status().Reason {
-		// this StatusReason explicitly requests the caller to delay the action
-		case StatusReasonServerTimeout:
-			return int(t.Status().Details.RetryAfterSeconds), true
-		}
-		// If the client requests that we retry after a certain number of seconds
-		if t.Status().Details.RetryAfterSeconds > 0 {
-			return int(t.Status().Details.RetryAfterSeconds), true
-		}
+	t := APIStatus(nil)
+	if !errors.As(err, &t) {
+		return 0, false
+	}
+	status := t.Status()
+	// If the client requests that we retry after a certain number of seconds
+	if status.Details != nil && status.Details.RetryAfterSeconds > 0 {
+		return int(status.Details.RetryAfterSeconds), true
+	}
+	if seconds := DefaultDelays[status.Reason]; seconds > 0 {
+		return seconds, true
+	}
+	// this StatusReason explicitly requests the caller to delay the action
+	if status.Details != nil && status.Reason == StatusReasonServerTimeout {
+		return 0, true
 	}
 	return 0, false
 }
@@ -548,11 +1184,35 @@ func ReasonForError(err error) StatusReason {
 	return StatusReasonUnknown
 }
 
-// ErrorToAPIStatus converts an error to an Status object.
+// CodeForError returns the HTTP status code for a particular error, or 0 if the error is not
+// of the type APIStatus.
+// It supports wrapped errors.
+func CodeForError(err error) int32 {
+	if status := APIStatus(nil); errors.As(err, &status) {
+		return status.Status().Code
+	}
+	return 0
+}
+
+// MessageForError returns the status message for a particular error, or an empty string if the
+// error is not of the type APIStatus.
+// It supports wrapped errors.
+func MessageForError(err error) string {
+	if status := APIStatus(nil); errors.As(err, &status) {
+		return status.Status().Message
+	}
+	return ""
+}
+
+// ErrorToAPIStatus converts an error to an Status object. Errors without a
+// status that report a Timeout() are converted to a Timeout, and those that
+// report being Temporary() to a ServerTimeout.
 func ErrorToAPIStatus(err error) *Status {
 	switch t := err.(type) {
 	case interface{ Status() Status }:
 		status := t.Status()
+		status.APIVersion = StatusAPIVersion
+		status.Kind = StatusKind
 		if len(status.Status) == 0 {
 			status.Status = StatusFailure
 		}
@@ -563,7 +1223,7 @@ func ErrorToAPIStatus(err error) *Status {
 			}
 		case StatusFailure:
 			if status.Code == 0 {
-				status.Code = http.StatusInternalServerError
+				status.Code = int32(HTTPCodeForReason(status.Reason))
 			}
 		default:
 			runtime.HandleError(fmt.Errorf("apiserver received an error with wrong status field : %#+v", err))
@@ -573,69 +1233,286 @@ func ErrorToAPIStatus(err error) *Status {
 		}
 		return &status
 	default:
-		status := http.StatusInternalServerError
+		reason := StatusReasonUnknown
+		// Network errors report whether they are timeouts or temporary, which
+		// clients may retry.
+		if timeout := (interface{ Timeout() bool })(nil); errors.As(err, &timeout) && timeout.Timeout() {
+			reason = StatusReasonTimeout
+		} else if temporary := (interface{ Temporary() bool })(nil); errors.As(err, &temporary) && temporary.Temporary() {
+			reason = StatusReasonServerTimeout
+		}
 		// Log errors that were not converted to an error status
 		// by REST storage - these typically indicate programmer
 		// error by not using pkg/api/errors, or unexpected failure
 		// cases.
 		return &Status{
-			Status:  StatusFailure,
-			Code:    int32(status),
-			Reason:  StatusReasonUnknown,
-			Message: err.Error(),
+			APIVersion: StatusAPIVersion,
+			Kind:       StatusKind,
+			Status:     StatusFailure,
+			Code:       int32(HTTPCodeForReason(reason)),
+			Reason:     reason,
+			Message:    err.Error(),
 		}
 	}
 }
 
+// MaxResponseBodyBytes is the maximum number of bytes that FromResponse will
+// read from a response body before giving up.
+var MaxResponseBodyBytes int64 = 4 << 20
+
 // FromResponse determines if the http.Response contains an error, if so, it
-// attempts to decode the error into a Status struct. If the decoding fails, an
-// internal error is returned
+// attempts to decode the error into a Status struct. Decoding is only attempted
+// when the response declares a JSON Content-Type (or none at all). If the body
+// is not a valid Status (e.g. a plaintext or HTML response from a proxy), a
+// generic server response error is built from the status code using the body
+// as the server message. When both the Retry-After header and the decoded
+// Status specify a retry delay, the longer of the two is used. A 429 response
+// without a Retry-After header is retried once its X-RateLimit-Reset time has
+// passed, see RateLimitInfoFromResponse.
 func FromResponse(resp *http.Response) (err error, hasError bool) {
+	return fromResponse(resp, false)
+}
+
+// FromResponseStrict behaves like FromResponse, except that a Status body containing fields that
+// are not modeled by the Status type results in an internal error describing the unknown field.
+// It is intended for detecting protocol mismatches while debugging.
+func FromResponseStrict(resp *http.Response) (err error, hasError bool) {
+	return fromResponse(resp, true)
+}
+
+// Check returns nil if the response has a 2xx status code, otherwise it returns
+// the *StatusError decoded from the response as described by FromResponse, so
+// that callers can simply check the response with:
+//
+//	if err := errors.Check(resp); err != nil {
+//		return err
+//	}
+func Check(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+	if err, hasError := FromResponse(resp); hasError {
+		return err
+	}
+	return nil
+}
+
+// fromResponse implements FromResponse and FromResponseStrict.
+func fromResponse(resp *http.Response, strict bool) (err error, hasError bool) {
 	if resp.StatusCode >= http.StatusOK && resp.StatusCode <= http.StatusNoContent {
 		return nil, false
 	}
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxResponseBodyBytes+1))
 	if err != nil {
 		return NewInternalError(fmt.Errorf("client error: reading server response: %w", err)), true
 	}
-	status := Status{}
-	err = json.Unmarshal(body, &status)
-	if err != nil {
-		return NewInternalError(fmt.Errorf("client error: unmarshalling server response: %w", err)), true
+	if int64(len(body)) > MaxResponseBodyBytes {
+		return NewInternalError(fmt.Errorf("client error: server response exceeded the %d byte limit", MaxResponseBodyBytes)), true
 	}
 	seconds, ok := retryAfterSeconds(resp)
+	if !ok && resp.StatusCode == http.StatusTooManyRequests {
+		// without a Retry-After header, wait until the rate limit resets
+		if info, found := RateLimitInfoFromResponse(resp); found {
+			seconds, ok = info.retryAfterSeconds()
+		}
+	}
+	var status *Status
+	if hasJSONContentType(resp) {
+		status, _ = ParseStatusJSON(body)
+	}
+	if status == nil {
+		return NewGenericServerResponse(resp.StatusCode, requestMethod(resp), "", strings.TrimSpace(string(body)), seconds, true), true
+	}
+	if strict {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&statusJSON{statusFields: &statusFields{}}); err != nil {
+			return NewInternalError(fmt.Errorf("client error: decoding server response: %w", err)), true
+		}
+	}
 	if ok {
+		// The header and the body may disagree; honor the longer of the two
+		// so that the client never retries sooner than either asked for.
 		if status.Details == nil {
 			status.Details = &StatusDetails{
 				RetryAfterSeconds: int32(seconds),
 			}
-		} else {
+		} else if int32(seconds) > status.Details.RetryAfterSeconds {
 			status.Details.RetryAfterSeconds = int32(seconds)
 		}
 	}
-	return &StatusError{ErrStatus: status}, true
+	return &StatusError{ErrStatus: *status}, true
 }
 
-// retryAfterSeconds returns the value of the Retry-After header and true, or 0 and false if
-// the header was missing or not a valid number.
-func retryAfterSeconds(resp *http.Response) (int, bool) {
-	if h := resp.Header.Get("Retry-After"); len(h) > 0 {
-		if i, err := strconv.Atoi(h); err == nil {
-			return i, true
+// ParseStatusJSON decodes a Status from its JSON wire format. It returns an
+// error, and never panics, for input that is malformed, truncated or not a
+// JSON object.
+func ParseStatusJSON(data []byte) (*Status, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil, fmt.Errorf("status must be a JSON object")
+	}
+	status := &Status{}
+	if err := json.Unmarshal(data, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// statusFields has the fields of Status without its UnmarshalJSON method.
+type statusFields Status
+
+// statusJSON is the wire form of a Status, in which the code may be either a
+// JSON number or a string, as sent by some gateways.
+type statusJSON struct {
+	*statusFields
+	Code json.RawMessage `json:"code,omitempty"`
+}
+
+// UnmarshalJSON decodes a Status, accepting the code as either a number
+// (404) or a string ("404").
+func (s *Status) UnmarshalJSON(data []byte) error {
+	wire := statusJSON{statusFields: (*statusFields)(s)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	return wire.decodeCode()
+}
+
+// decodeCode sets the code of the underlying Status from its wire form.
+func (w *statusJSON) decodeCode() error {
+	raw := bytes.TrimSpace(w.Code)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	if raw[0] == '"' {
+		var code string
+		if err := json.Unmarshal(raw, &code); err != nil {
+			return err
+		}
+		parsed, err := strconv.ParseInt(strings.TrimSpace(code), 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid status code %q: %w", code, err)
 		}
+		w.statusFields.Code = int32(parsed)
+		return nil
+	}
+	return json.Unmarshal(raw, &w.statusFields.Code)
+}
+
+// StatusToJSON encodes the status in the same wire format that FromResponse
+// expects to decode.
+func StatusToJSON(status *Status) ([]byte, error) {
+	return json.MarshalIndent(status, "", "  ")
+}
+
+// WriteResponse writes e to w in a wire format that FromResponse can parse,
+// including the Retry-After header when the status suggests a retry period.
+// It allows servers to emit errors without depending on the httputils package.
+func (e *StatusError) WriteResponse(w http.ResponseWriter) {
+	status := ErrorToAPIStatus(e)
+	body, err := StatusToJSON(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if status.Details != nil && status.Details.RetryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(status.Details.RetryAfterSeconds)))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(status.Code))
+	w.Write(body)
+}
+
+// hasJSONContentType returns true if the response declares a JSON Content-Type
+// or does not declare a Content-Type at all.
+func hasJSONContentType(resp *http.Response) bool {
+	h := resp.Header.Get("Content-Type")
+	if len(h) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(h)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json"
+}
+
+// requestMethod returns the method of the request that produced resp, or an
+// empty string if the request is not available.
+func requestMethod(resp *http.Response) string {
+	if resp.Request == nil {
+		return ""
+	}
+	return resp.Request.Method
+}
+
+// now returns the current time and may be replaced in tests.
+var now = time.Now
+
+// RetryAfterSeconds returns the value of the Retry-After header and true, or 0 and false if
+// the header was missing or invalid. Both the delta-seconds and the HTTP-date forms of the
+// header are supported, dates in the past yield zero seconds.
+func RetryAfterSeconds(resp *http.Response) (int, bool) {
+	h := resp.Header.Get("Retry-After")
+	if len(h) == 0 {
+		return 0, false
+	}
+	if i, err := strconv.Atoi(h); err == nil {
+		return i, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		seconds := int(t.Sub(now()).Seconds())
+		if seconds < 0 {
+			seconds = 0
+		}
+		return seconds, true
 	}
 	return 0, false
 }
 
-// checkWait returns true along with a number of seconds if the server instructed us to wait
+// CheckWait returns true along with a number of seconds if the server instructed us to wait
 // before retrying.
-func checkWait(resp *http.Response) (int, bool) {
+func CheckWait(resp *http.Response) (int, bool) {
 	switch r := resp.StatusCode; {
 	// any 500 error code and 429 can trigger a wait
 	case r == http.StatusTooManyRequests, r >= 500:
 	default:
 		return 0, false
 	}
-	i, ok := retryAfterSeconds(resp)
+	i, ok := RetryAfterSeconds(resp)
 	return i, ok
 }
+
+// SuggestsClientDelayFromResponse returns the delay that a 429 or 503 response asks the
+// client to wait before retrying, from either the delta-seconds or the HTTP-date form of
+// its Retry-After header, and true. Unlike RetryAfterSeconds, the delay until an HTTP-date
+// is not rounded to whole seconds. Returns 0 and false for other status codes and when
+// the header is missing or invalid.
+func SuggestsClientDelayFromResponse(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	h := resp.Header.Get("Retry-After")
+	if i, err := strconv.Atoi(h); err == nil && i >= 0 {
+		return time.Duration(i) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		delay := t.Sub(now())
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// retryAfterSeconds delegates to RetryAfterSeconds.
+func retryAfterSeconds(resp *http.Response) (int, bool) {
+	return RetryAfterSeconds(resp)
+}
+
+// checkWait delegates to CheckWait.
+func checkWait(resp *http.Response) (int, bool) {
+	return CheckWait(resp)
+}
@@ -1,12 +1,20 @@
 package errors
 
 import (
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestErrorNew(t *testing.T) {
@@ -60,6 +68,72 @@ func TestErrorNew(t *testing.T) {
 	if !IsMethodNotSupported(NewMethodNotSupported("delete")) {
 		t.Errorf("expected to be %s", StatusReasonMethodNotAllowed)
 	}
+	if !IsPreconditionFailed(NewPreconditionFailed("tests", "version mismatch")) {
+		t.Errorf("expected to be %s", StatusReasonPreconditionFailed)
+	}
+	if !IsPreconditionFailed(fmt.Errorf("wrapping: %w", NewPreconditionFailed("tests", "version mismatch"))) {
+		t.Errorf("expected wrapped error to be %s", StatusReasonPreconditionFailed)
+	}
+	if !IsPreconditionFailed(NewGenericServerResponse(http.StatusPreconditionFailed, "PUT", "tests", "", 0, false)) {
+		t.Errorf("expected generic 412 to be %s", StatusReasonPreconditionFailed)
+	}
+	if !IsRequestTimeout(NewRequestTimeout("slow upload", 0)) {
+		t.Errorf("expected to be %s", StatusReasonRequestTimeout)
+	}
+	if !IsRequestTimeout(fmt.Errorf("wrapping: %w", NewRequestTimeout("slow upload", 0))) {
+		t.Errorf("expected wrapped error to be %s", StatusReasonRequestTimeout)
+	}
+	if !IsRequestTimeout(NewGenericServerResponse(http.StatusRequestTimeout, "PUT", "tests", "", 0, false)) {
+		t.Errorf("expected generic 408 to be %s", StatusReasonRequestTimeout)
+	}
+	if IsRequestTimeout(NewTimeoutError("test reason", 0)) {
+		t.Errorf("expected gateway timeout to not be %s", StatusReasonRequestTimeout)
+	}
+	if !IsClientClosedRequest(NewClientClosedRequest("canceled")) {
+		t.Errorf("expected to be %s", StatusReasonClientClosedRequest)
+	}
+	if !IsClientClosedRequest(fmt.Errorf("wrapping: %w", NewClientClosedRequest("canceled"))) {
+		t.Errorf("expected wrapped error to be %s", StatusReasonClientClosedRequest)
+	}
+	if !IsPaymentRequired(NewPaymentRequired("workspace is past due")) {
+		t.Errorf("expected to be %s", StatusReasonPaymentRequired)
+	}
+	if !IsPaymentRequired(fmt.Errorf("wrapping: %w", NewPaymentRequired("workspace is past due"))) {
+		t.Errorf("expected wrapped error to be %s", StatusReasonPaymentRequired)
+	}
+	if !IsPaymentRequired(NewGenericServerResponse(http.StatusPaymentRequired, "GET", "tests", "", 0, false)) {
+		t.Errorf("expected generic 402 to be %s", StatusReasonPaymentRequired)
+	}
+	if code := CodeForError(NewPaymentRequired("workspace is past due")); code != http.StatusPaymentRequired {
+		t.Errorf("expected code %d, got %d", http.StatusPaymentRequired, code)
+	}
+	if !IsLocked(NewLocked("tests", "held by another client")) {
+		t.Errorf("expected to be %s", StatusReasonLocked)
+	}
+	if !IsLocked(fmt.Errorf("wrapping: %w", NewLocked("tests", "held by another client"))) {
+		t.Errorf("expected wrapped error to be %s", StatusReasonLocked)
+	}
+	if !IsLocked(NewGenericServerResponse(http.StatusLocked, "PUT", "tests", "", 0, false)) {
+		t.Errorf("expected generic %d to be %s", http.StatusLocked, StatusReasonLocked)
+	}
+	if code := CodeForError(NewLocked("tests", "held by another client")); code != http.StatusLocked {
+		t.Errorf("expected code %d, got %d", http.StatusLocked, code)
+	}
+	if !IsFailedDependency(NewFailedDependency("tests", "the copy failed")) {
+		t.Errorf("expected to be %s", StatusReasonFailedDependency)
+	}
+	if !IsFailedDependency(fmt.Errorf("wrapping: %w", NewFailedDependency("tests", "the copy failed"))) {
+		t.Errorf("expected wrapped error to be %s", StatusReasonFailedDependency)
+	}
+	if !IsFailedDependency(NewGenericServerResponse(http.StatusFailedDependency, "PUT", "tests", "", 0, false)) {
+		t.Errorf("expected generic %d to be %s", http.StatusFailedDependency, StatusReasonFailedDependency)
+	}
+	if code := CodeForError(NewFailedDependency("tests", "the copy failed")); code != http.StatusFailedDependency {
+		t.Errorf("expected code %d, got %d", http.StatusFailedDependency, code)
+	}
+	if IsPreconditionFailed(err) {
+		t.Errorf("expected to not be %s", StatusReasonPreconditionFailed)
+	}
 
 	if time, ok := SuggestsClientDelay(NewServerTimeout("doing something", 10)); time != 10 || !ok {
 		t.Errorf("unexpected %d", time)
@@ -70,6 +144,15 @@ func TestErrorNew(t *testing.T) {
 	if time, ok := SuggestsClientDelay(NewTimeoutError("test reason", 10)); time != 10 || !ok {
 		t.Errorf("unexpected %d", time)
 	}
+	if time, ok := SuggestsClientDelay(NewRequestTimeout("test reason", 10)); time != 10 || !ok {
+		t.Errorf("unexpected %d", time)
+	}
+	if time, ok := SuggestsClientDelay(NewRequestTimeout("test reason", 0)); time != 0 || ok {
+		t.Errorf("unexpected %d", time)
+	}
+	if time, ok := SuggestsClientDelay(NewGenericServerResponse(408, "put", "tests", "doing something", 5, true)); time != 5 || !ok {
+		t.Errorf("unexpected %d", time)
+	}
 	if time, ok := SuggestsClientDelay(NewTooManyRequests("doing something", 10)); time != 10 || !ok {
 		t.Errorf("unexpected %d", time)
 	}
@@ -99,6 +182,7 @@ func TestNewInvalid(t *testing.T) {
 				Causes: []StatusCause{{
 					Type:  CauseTypeFieldValueDuplicate,
 					Field: "field[0].name",
+					Value: "bar",
 				}},
 			},
 		},
@@ -109,6 +193,7 @@ func TestNewInvalid(t *testing.T) {
 				Causes: []StatusCause{{
 					Type:  CauseTypeFieldValueInvalid,
 					Field: "field[0].name",
+					Value: "bar",
 				}},
 			},
 		},
@@ -119,6 +204,7 @@ func TestNewInvalid(t *testing.T) {
 				Causes: []StatusCause{{
 					Type:  CauseTypeFieldValueNotFound,
 					Field: "field[0].name",
+					Value: "bar",
 				}},
 			},
 		},
@@ -129,6 +215,7 @@ func TestNewInvalid(t *testing.T) {
 				Causes: []StatusCause{{
 					Type:  CauseTypeFieldValueNotSupported,
 					Field: "field[0].name",
+					Value: "bar",
 				}},
 			},
 		},
@@ -163,6 +250,30 @@ func TestReasonForError(t *testing.T) {
 	}
 }
 
+func TestCodeForError(t *testing.T) {
+	if e, a := int32(0), CodeForError(nil); e != a {
+		t.Errorf("unexpected code: %d", a)
+	}
+	if e, a := int32(0), CodeForError(errors.New("some other error")); e != a {
+		t.Errorf("unexpected code: %d", a)
+	}
+	if e, a := int32(http.StatusNotFound), CodeForError(fmt.Errorf("wrapping: %w", NewNotFound("tests", ""))); e != a {
+		t.Errorf("unexpected code: %d", a)
+	}
+}
+
+func TestMessageForError(t *testing.T) {
+	if e, a := "", MessageForError(nil); e != a {
+		t.Errorf("unexpected message: %q", a)
+	}
+	if e, a := "", MessageForError(errors.New("some other error")); e != a {
+		t.Errorf("unexpected message: %q", a)
+	}
+	if e, a := "tests not found", MessageForError(fmt.Errorf("wrapping: %w", NewNotFound("tests", ""))); e != a {
+		t.Errorf("unexpected message: %q", a)
+	}
+}
+
 type TestType struct{}
 
 func TestFromObject(t *testing.T) {
@@ -437,3 +548,1356 @@ func TestSuggestsClientDelaySupportsWrapping(t *testing.T) {
 		})
 	}
 }
+
+func TestCheck(t *testing.T) {
+	notFound, err := json.Marshal(NewNotFound("tests", "1").ErrStatus)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		code           int
+		contentType    string
+		body           string
+		expectedReason StatusReason
+		expectedCode   int32
+	}{
+		{"OK", http.StatusOK, "application/json", `{"name":"foo"}`, "", 0},
+		{"Accepted", http.StatusAccepted, "", "", "", 0},
+		{"NotFound", http.StatusNotFound, "application/json", string(notFound), StatusReasonNotFound, http.StatusNotFound},
+		{"NonJSON", http.StatusInternalServerError, "text/plain", "boom", StatusReasonInternalError, http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tc.code,
+				Header:     http.Header{"Content-Type": []string{tc.contentType}},
+				Body:       ioutil.NopCloser(strings.NewReader(tc.body)),
+			}
+			err := Check(resp)
+			if tc.expectedCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("expected a *StatusError, got %#v", err)
+			}
+			if reason := ReasonForError(err); reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+			if code := CodeForError(err); code != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, code)
+			}
+		})
+	}
+}
+
+func TestFromResponseNonJSONBody(t *testing.T) {
+	testCases := []struct {
+		name           string
+		code           int
+		body           string
+		expectedReason StatusReason
+		expectedCause  string
+	}{
+		{
+			name:           "HTML body",
+			code:           http.StatusBadGateway,
+			body:           "<html><body>502 Bad Gateway</body></html>",
+			expectedReason: StatusReasonInternalError,
+			expectedCause:  "<html><body>502 Bad Gateway</body></html>",
+		},
+		{
+			name:           "Plaintext body",
+			code:           http.StatusServiceUnavailable,
+			body:           "upstream unavailable\n",
+			expectedReason: StatusReasonServiceUnavailable,
+			expectedCause:  "upstream unavailable",
+		},
+		{
+			name:           "Empty body",
+			code:           http.StatusNotFound,
+			body:           "",
+			expectedReason: StatusReasonNotFound,
+			expectedCause:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tc.code,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(tc.body)),
+			}
+			err, hasError := FromResponse(resp)
+			if !hasError {
+				t.Fatalf("expected an error")
+			}
+			if reason := ReasonForError(err); reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+			if !IsUnexpectedServerError(err) {
+				t.Errorf("expected an unexpected server error")
+			}
+			cause, _ := GetStatusCause(err, CauseTypeUnexpectedServerResponse)
+			if cause.Message != tc.expectedCause {
+				t.Errorf("expected cause message %q, got %q", tc.expectedCause, cause.Message)
+			}
+		})
+	}
+}
+
+func TestFromResponseStatusBody(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"status":"Failure","message":"tests not found","reason":"NotFound","code":404}`)),
+	}
+	err, hasError := FromResponse(resp)
+	if !hasError {
+		t.Fatalf("expected an error")
+	}
+	if !IsNotFound(err) {
+		t.Errorf("expected to be %s", StatusReasonNotFound)
+	}
+	if IsUnexpectedServerError(err) {
+		t.Errorf("expected a decoded status error")
+	}
+	if err.Error() != "tests not found" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestFromResponseContentType(t *testing.T) {
+	body := `{"status":"Failure","message":"tests not found","reason":"NotFound","code":404}`
+	testCases := []struct {
+		name             string
+		contentType      string
+		expectUnexpected bool
+	}{
+		{
+			name:             "JSON",
+			contentType:      "application/json",
+			expectUnexpected: false,
+		},
+		{
+			name:             "JSON with charset",
+			contentType:      "application/json; charset=utf-8",
+			expectUnexpected: false,
+		},
+		{
+			name:             "Unspecified",
+			contentType:      "",
+			expectUnexpected: false,
+		},
+		{
+			name:             "HTML",
+			contentType:      "text/html",
+			expectUnexpected: true,
+		},
+		{
+			name:             "Plaintext",
+			contentType:      "text/plain; charset=utf-8",
+			expectUnexpected: true,
+		},
+		{
+			name:             "Malformed",
+			contentType:      "application/json;;",
+			expectUnexpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}
+			if len(tc.contentType) > 0 {
+				resp.Header.Set("Content-Type", tc.contentType)
+			}
+			err, hasError := FromResponse(resp)
+			if !hasError {
+				t.Fatalf("expected an error")
+			}
+			if !IsNotFound(err) {
+				t.Errorf("expected to be %s", StatusReasonNotFound)
+			}
+			if result := IsUnexpectedServerError(err); result != tc.expectUnexpected {
+				t.Errorf("expected unexpected server error: %t, got: %t", tc.expectUnexpected, result)
+			}
+		})
+	}
+}
+
+func TestFromResponseBodyLimit(t *testing.T) {
+	defer func(limit int64) { MaxResponseBodyBytes = limit }(MaxResponseBodyBytes)
+	MaxResponseBodyBytes = 16
+
+	resp := &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(strings.Repeat("x", 1024))),
+	}
+	err, hasError := FromResponse(resp)
+	if !hasError {
+		t.Fatalf("expected an error")
+	}
+	if !IsInternalError(err) {
+		t.Errorf("expected to be %s", StatusReasonInternalError)
+	}
+	if !strings.Contains(err.Error(), "exceeded the 16 byte limit") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	resp = &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(strings.Repeat("x", 16))),
+	}
+	err, _ = FromResponse(resp)
+	if !IsUnexpectedServerError(err) {
+		t.Errorf("expected a body at the limit to be read")
+	}
+}
+
+func TestWriteResponseRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  *StatusError
+	}{
+		{name: "NotFound", err: NewNotFound("tests", "1")},
+		{name: "Invalid", err: NewInvalid("tests", field.ErrorList{field.Required(field.NewPath("name"), "")})},
+		{name: "TooManyRequests", err: NewTooManyRequests("slow down", 10)},
+		{name: "DocumentationURL", err: NewBadRequest("bad").WithDocumentationURL("https://example.com/docs/errors#bad-request")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tc.err.WriteResponse(recorder)
+
+			err, hasError := FromResponse(recorder.Result())
+			if !hasError {
+				t.Fatalf("expected an error")
+			}
+			statusErr, ok := err.(*StatusError)
+			if !ok {
+				t.Fatalf("expected a *StatusError, got %T", err)
+			}
+			if !reflect.DeepEqual(tc.err.ErrStatus, statusErr.ErrStatus) {
+				t.Errorf("expected %#v, got %#v", tc.err.ErrStatus, statusErr.ErrStatus)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name        string
+		err         error
+		expectMatch bool
+	}{
+		{name: "ServerTimeout", err: NewServerTimeout("tests", 0), expectMatch: true},
+		{name: "Timeout", err: NewTimeoutError("tests", 0), expectMatch: true},
+		{name: "TooManyRequests", err: NewTooManyRequests("tests", 0), expectMatch: true},
+		{name: "TooManyRequests via status code", err: &StatusError{ErrStatus: Status{Code: http.StatusTooManyRequests}}, expectMatch: true},
+		{name: "ServiceUnavailable", err: NewServiceUnavailable("tests"), expectMatch: true},
+		{name: "InternalError", err: NewInternalError(errors.New("tests")), expectMatch: true},
+		{name: "Generic 502", err: NewGenericServerResponse(http.StatusBadGateway, "GET", "tests", "", 0, true), expectMatch: true},
+		{name: "NotFound", err: NewNotFound("tests", ""), expectMatch: false},
+		{name: "Invalid", err: NewInvalid("tests", nil), expectMatch: false},
+		{name: "Forbidden", err: NewForbidden("tests", errors.New("reason")), expectMatch: false},
+		{name: "Conflict", err: NewConflict("tests", errors.New("reason")), expectMatch: false},
+		{name: "BadRequest", err: NewBadRequest("tests"), expectMatch: false},
+		{name: "Non-API error", err: errors.New("some other error"), expectMatch: false},
+		{name: "Nested match", err: fmt.Errorf("wrapping: %w", NewServiceUnavailable("tests")), expectMatch: true},
+		{name: "Nested, no match", err: fmt.Errorf("wrapping: %w", NewNotFound("tests", "")), expectMatch: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := IsRetryable(tc.err); result != tc.expectMatch {
+				t.Errorf("expected match: %t, but got match: %t", tc.expectMatch, result)
+			}
+		})
+	}
+}
+
+func TestNewAggregate(t *testing.T) {
+	notFound := NewNotFound("tests", "1")
+	invalid := NewInvalid("tests", field.ErrorList{field.Required(field.NewPath("name"), "")})
+
+	err := NewAggregate([]error{notFound, nil, invalid})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !IsInvalid(err) {
+		t.Errorf("expected to be %s", StatusReasonInvalid)
+	}
+	if err.ErrStatus.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected code %d", err.ErrStatus.Code)
+	}
+	expectedCauses := []StatusCause{
+		{Message: notFound.ErrStatus.Message},
+		invalid.ErrStatus.Details.Causes[0],
+	}
+	if !reflect.DeepEqual(expectedCauses, err.ErrStatus.Details.Causes) {
+		t.Errorf("expected %#v, got %#v", expectedCauses, err.ErrStatus.Details.Causes)
+	}
+	expectedMessage := fmt.Sprintf("[%s, %s]", notFound.Error(), invalid.Error())
+	if err.Error() != expectedMessage {
+		t.Errorf("expected message %q, got %q", expectedMessage, err.Error())
+	}
+
+	if err := NewAggregate([]error{errors.New("boom"), notFound}); err.ErrStatus.Code != http.StatusInternalServerError {
+		t.Errorf("expected the internal error to take precedence, got %#v", err.ErrStatus)
+	}
+	if err := NewAggregate(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC) }
+
+	testCases := []struct {
+		name            string
+		header          string
+		expectedSeconds int
+		expectedOk      bool
+	}{
+		{name: "Numeric", header: "10", expectedSeconds: 10, expectedOk: true},
+		{name: "Missing", header: "", expectedSeconds: 0, expectedOk: false},
+		{name: "HTTP-date", header: "Wed, 21 Oct 2015 07:28:30 GMT", expectedSeconds: 30, expectedOk: true},
+		{name: "HTTP-date in the past", header: "Wed, 21 Oct 2015 07:27:00 GMT", expectedSeconds: 0, expectedOk: true},
+		{name: "Malformed", header: "soon", expectedSeconds: 0, expectedOk: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			if len(tc.header) > 0 {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			if seconds, ok := RetryAfterSeconds(resp); seconds != tc.expectedSeconds || ok != tc.expectedOk {
+				t.Errorf("expected (%d, %t), got (%d, %t)", tc.expectedSeconds, tc.expectedOk, seconds, ok)
+			}
+		})
+	}
+}
+
+func TestCheckWait(t *testing.T) {
+	testCases := []struct {
+		name            string
+		code            int
+		header          string
+		expectedSeconds int
+		expectedOk      bool
+	}{
+		{name: "TooManyRequests", code: http.StatusTooManyRequests, header: "10", expectedSeconds: 10, expectedOk: true},
+		{name: "ServiceUnavailable", code: http.StatusServiceUnavailable, header: "5", expectedSeconds: 5, expectedOk: true},
+		{name: "Missing header", code: http.StatusServiceUnavailable, header: "", expectedSeconds: 0, expectedOk: false},
+		{name: "Client error", code: http.StatusNotFound, header: "10", expectedSeconds: 0, expectedOk: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.code, Header: http.Header{}}
+			if len(tc.header) > 0 {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			if seconds, ok := CheckWait(resp); seconds != tc.expectedSeconds || ok != tc.expectedOk {
+				t.Errorf("expected (%d, %t), got (%d, %t)", tc.expectedSeconds, tc.expectedOk, seconds, ok)
+			}
+		})
+	}
+}
+
+func TestSuggestsClientDelayFromResponse(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2015, 10, 21, 7, 28, 0, 500000000, time.UTC) }
+
+	testCases := []struct {
+		name          string
+		code          int
+		header        string
+		expectedDelay time.Duration
+		expectedOk    bool
+	}{
+		{name: "Seconds", code: http.StatusTooManyRequests, header: "10", expectedDelay: 10 * time.Second, expectedOk: true},
+		{name: "HTTP-date", code: http.StatusServiceUnavailable, header: "Wed, 21 Oct 2015 07:28:30 GMT", expectedDelay: 29500 * time.Millisecond, expectedOk: true},
+		{name: "HTTP-date in the past", code: http.StatusTooManyRequests, header: "Wed, 21 Oct 2015 07:27:00 GMT", expectedDelay: 0, expectedOk: true},
+		{name: "Missing header", code: http.StatusServiceUnavailable, header: "", expectedDelay: 0, expectedOk: false},
+		{name: "Malformed", code: http.StatusTooManyRequests, header: "soon", expectedDelay: 0, expectedOk: false},
+		{name: "Client error", code: http.StatusNotFound, header: "10", expectedDelay: 0, expectedOk: false},
+		{name: "Internal error", code: http.StatusInternalServerError, header: "10", expectedDelay: 0, expectedOk: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.code, Header: http.Header{}}
+			if len(tc.header) > 0 {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			if delay, ok := SuggestsClientDelayFromResponse(resp); delay != tc.expectedDelay || ok != tc.expectedOk {
+				t.Errorf("expected (%v, %t), got (%v, %t)", tc.expectedDelay, tc.expectedOk, delay, ok)
+			}
+		})
+	}
+}
+
+func TestStatusCauseMarshal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cause    StatusCause
+		expected string
+	}{
+		{
+			name:     "Without value",
+			cause:    StatusCause{Type: CauseTypeFieldValueRequired, Message: "required", Field: "name"},
+			expected: `{"reason":"FieldValueRequired","message":"required","field":"name"}`,
+		},
+		{
+			name:     "With value",
+			cause:    CauseWithValue(CauseTypeFieldValueInvalid, "name", "invalid", "bar"),
+			expected: `{"reason":"FieldValueInvalid","message":"invalid","field":"name","value":"bar"}`,
+		},
+		{
+			name:     "With error code",
+			cause:    StatusCause{Type: CauseTypeFieldValueInvalid, Field: "name", ErrorCode: "E100"},
+			expected: `{"reason":"FieldValueInvalid","field":"name","errorCode":"E100"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := json.Marshal(tc.cause)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(out) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, out)
+			}
+			var decoded StatusCause
+			if err := json.Unmarshal(out, &decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.cause, decoded) {
+				t.Errorf("expected %#v, got %#v", tc.cause, decoded)
+			}
+		})
+	}
+}
+
+// update rewrites the golden files in testdata with the actual output.
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestStatusGolden(t *testing.T) {
+	status := Status{
+		APIVersion: StatusAPIVersion,
+		Kind:       StatusKind,
+		Status:     StatusFailure,
+		Message:    "tests \"foo\" is invalid",
+		Reason:     StatusReasonInvalid,
+		Details: &StatusDetails{
+			Name: "foo",
+			UID:  "1",
+			Causes: []StatusCause{{
+				Type:      CauseTypeFieldValueInvalid,
+				Message:   "name is invalid",
+				Field:     "name",
+				Value:     "Foo",
+				ErrorCode: "E1001",
+			}},
+			RetryAfterSeconds: 5,
+		},
+		Code: http.StatusUnprocessableEntity,
+	}
+	out, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out = append(out, '\n')
+
+	golden := filepath.Join("testdata", "status.golden.json")
+	if *update {
+		if err := ioutil.WriteFile(golden, out, 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != string(expected) {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+
+	decoded, err := ParseStatusJSON(expected)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(&status, decoded) {
+		t.Errorf("expected %#v, got %#v", &status, decoded)
+	}
+}
+
+func TestStatusErrorDeepCopy(t *testing.T) {
+	original := NewInvalid("tests", field.ErrorList{field.Required(field.NewPath("name"), "")})
+	expected := original.DeepCopy()
+	if !reflect.DeepEqual(original, expected) {
+		t.Fatalf("expected %#v, got %#v", original, expected)
+	}
+
+	copied := original.DeepCopy()
+	copied.ErrStatus.Message = "changed"
+	copied.ErrStatus.Details.Name = "changed"
+	copied.ErrStatus.Details.Causes[0].Message = "changed"
+	copied.ErrStatus.Details.Causes = append(copied.ErrStatus.Details.Causes, StatusCause{Message: "added"})
+	if !reflect.DeepEqual(expected, original) {
+		t.Errorf("expected the original to be unchanged, got %#v", original)
+	}
+
+	var nilErr *StatusError
+	if nilErr.DeepCopy() != nil {
+		t.Errorf("expected a nil copy")
+	}
+}
+
+func TestStatusErrorWithMessage(t *testing.T) {
+	original := NewForbidden("tests", errors.New("reason"))
+	message := original.Error()
+
+	customized := original.WithMessage("custom message")
+	if customized.Error() != "custom message" {
+		t.Errorf("unexpected message %q", customized.Error())
+	}
+	if !IsForbidden(customized) {
+		t.Errorf("expected to be %s", StatusReasonForbidden)
+	}
+	customized.ErrStatus.Details.Name = "changed"
+	if original.Error() != message || original.ErrStatus.Details.Name != "tests" {
+		t.Errorf("expected the original to be unchanged, got %#v", original)
+	}
+	if (*StatusError)(nil).WithMessage("custom message") != nil {
+		t.Errorf("expected nil")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	original := NewTimeoutError("request timed out", 5)
+	err := WithContext(original, "while syncing node %s", "foo")
+	if err.Error() != "while syncing node foo: Timeout: request timed out" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if err.ErrStatus.Reason != original.ErrStatus.Reason || err.ErrStatus.Code != original.ErrStatus.Code {
+		t.Errorf("expected reason %s and code %d, got %s and %d", original.ErrStatus.Reason, original.ErrStatus.Code, err.ErrStatus.Reason, err.ErrStatus.Code)
+	}
+	if !reflect.DeepEqual(original.ErrStatus.Details, err.ErrStatus.Details) {
+		t.Errorf("expected details %#v, got %#v", original.ErrStatus.Details, err.ErrStatus.Details)
+	}
+	if original.Error() != "Timeout: request timed out" {
+		t.Errorf("expected the original to be unchanged, got %q", original.Error())
+	}
+	if WithContext(nil, "context") != nil {
+		t.Errorf("expected nil")
+	}
+}
+
+func TestNewForbiddenWithCause(t *testing.T) {
+	cause := errors.New("token expired at 12:00")
+	err := NewForbiddenWithCause("tests", "insufficient permissions", cause)
+	if !IsForbidden(err) {
+		t.Errorf("expected to be %s", StatusReasonForbidden)
+	}
+	if err.Error() != "forbidden: insufficient permissions" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("expected the error to wrap the cause")
+	}
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), cause) {
+		t.Errorf("expected the wrapped error to wrap the cause")
+	}
+	if errors.Unwrap(err) != cause {
+		t.Errorf("expected Unwrap to return the cause, got %v", errors.Unwrap(err))
+	}
+	if errors.Unwrap(NewNotFound("tests", "1")) != nil {
+		t.Errorf("expected no cause")
+	}
+}
+
+func TestStatusErrorUnwrap(t *testing.T) {
+	cause := &os.PathError{Op: "open", Path: "/tmp/tests", Err: os.ErrPermission}
+	testCases := []struct {
+		name   string
+		err    *StatusError
+		reason StatusReason
+	}{
+		{"forbidden", NewForbidden("tests", cause), StatusReasonForbidden},
+		{"conflict", NewConflict("tests", cause), StatusReasonConflict},
+		{"internal", NewInternalError(cause), StatusReasonInternalError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if ReasonForError(tc.err) != tc.reason {
+				t.Errorf("expected reason %s, got %s", tc.reason, ReasonForError(tc.err))
+			}
+			if !errors.Is(tc.err, os.ErrPermission) {
+				t.Errorf("expected the error to wrap %v", os.ErrPermission)
+			}
+			var pathErr *os.PathError
+			if !errors.As(tc.err, &pathErr) || pathErr != cause {
+				t.Errorf("expected the error to wrap %v", cause)
+			}
+			if errors.Unwrap(tc.err.DeepCopy()) != cause {
+				t.Errorf("expected the copy to wrap %v", cause)
+			}
+
+			out, err := json.Marshal(tc.err)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected, err := json.Marshal(&StatusError{ErrStatus: tc.err.ErrStatus})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(out) != string(expected) {
+				t.Errorf("expected %s, got %s", expected, out)
+			}
+		})
+	}
+}
+
+func TestWithDocumentationURL(t *testing.T) {
+	original := NewNotFound("tests", "")
+	err := original.WithDocumentationURL("https://example.com/a").WithDocumentationURL("https://example.com/b")
+	if original.ErrStatus.Details.Causes != nil {
+		t.Errorf("expected the original error to be unmodified, got %#v", original.ErrStatus.Details)
+	}
+	if causes := GetStatusCauses(err, CauseTypeDocumentationURL); len(causes) != 1 {
+		t.Errorf("expected a single documentation cause, got %#v", causes)
+	}
+
+	recorder := httptest.NewRecorder()
+	err.WriteResponse(recorder)
+	decoded, _ := FromResponse(recorder.Result())
+	if url, ok := DocumentationURLForError(fmt.Errorf("wrapping: %w", decoded)); url != "https://example.com/b" || !ok {
+		t.Errorf("expected the documentation URL, got %q, %v", url, ok)
+	}
+
+	if url, ok := DocumentationURLForError(original); url != "" || ok {
+		t.Errorf("expected no documentation URL, got %q, %v", url, ok)
+	}
+	if out, _ := json.Marshal(original.ErrStatus); strings.Contains(string(out), string(CauseTypeDocumentationURL)) {
+		t.Errorf("expected no documentation URL in %s", out)
+	}
+	if (*StatusError)(nil).WithDocumentationURL("https://example.com") != nil {
+		t.Errorf("expected nil")
+	}
+}
+
+func TestNewInvalidFromMap(t *testing.T) {
+	err := NewInvalidFromMap("tests", map[string]string{
+		"spec.name":  "must not be empty",
+		"metadata.a": "must be lowercase",
+	})
+	equivalent := NewInvalid("tests", field.ErrorList{
+		field.Invalid(field.NewPath("metadata", "a"), "", "must be lowercase"),
+		field.Invalid(field.NewPath("spec", "name"), "", "must not be empty"),
+	})
+
+	if err.ErrStatus.Code != equivalent.ErrStatus.Code || err.ErrStatus.Reason != equivalent.ErrStatus.Reason {
+		t.Errorf("expected %#v, got %#v", equivalent.ErrStatus, err.ErrStatus)
+	}
+	if err.ErrStatus.Details.Name != equivalent.ErrStatus.Details.Name {
+		t.Errorf("expected name %q, got %q", equivalent.ErrStatus.Details.Name, err.ErrStatus.Details.Name)
+	}
+	if len(err.ErrStatus.Details.Causes) != len(equivalent.ErrStatus.Details.Causes) {
+		t.Fatalf("expected %d causes, got %d", len(equivalent.ErrStatus.Details.Causes), len(err.ErrStatus.Details.Causes))
+	}
+	for i, cause := range err.ErrStatus.Details.Causes {
+		expected := equivalent.ErrStatus.Details.Causes[i]
+		if cause.Type != expected.Type || cause.Field != expected.Field {
+			t.Errorf("%d: expected %#v, got %#v", i, expected, cause)
+		}
+	}
+	expectedMessage := "tests is invalid: [metadata.a: must be lowercase, spec.name: must not be empty]"
+	if err.Error() != expectedMessage {
+		t.Errorf("expected message %q, got %q", expectedMessage, err.Error())
+	}
+
+	for _, fieldErrors := range []map[string]string{nil, {}} {
+		err := NewInvalidFromMap("tests", fieldErrors)
+		if !IsInvalid(err) {
+			t.Errorf("expected to be %s", StatusReasonInvalid)
+		}
+		if err.Error() != "tests is invalid" {
+			t.Errorf("expected message %q, got %q", "tests is invalid", err.Error())
+		}
+		if len(err.ErrStatus.Details.Causes) != 0 {
+			t.Errorf("expected no causes, got %#v", err.ErrStatus.Details.Causes)
+		}
+	}
+}
+
+func TestNewInvalidFromCauses(t *testing.T) {
+	causes := []StatusCause{
+		{Type: CauseTypeFieldValueRequired, Field: "spec.name", Message: "must not be empty"},
+		{Type: CauseTypeFieldValueInvalid, Field: "spec.size", Message: "must be positive"},
+	}
+	err := NewInvalidFromCauses("tests", causes)
+	if !IsInvalid(err) {
+		t.Errorf("expected to be %s", StatusReasonInvalid)
+	}
+	if err.ErrStatus.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected code %d, got %d", http.StatusUnprocessableEntity, err.ErrStatus.Code)
+	}
+	if err.ErrStatus.Details.Name != "tests" || !reflect.DeepEqual(causes, err.ErrStatus.Details.Causes) {
+		t.Errorf("unexpected details %#v", err.ErrStatus.Details)
+	}
+	expectedMessage := "tests is invalid: [spec.name: must not be empty, spec.size: must be positive]"
+	if err.Error() != expectedMessage {
+		t.Errorf("expected message %q, got %q", expectedMessage, err.Error())
+	}
+}
+
+func TestDetailsFromError(t *testing.T) {
+	notFound := NewNotFound("tests", "1")
+	testCases := []struct {
+		name            string
+		err             error
+		expectedDetails *StatusDetails
+		expectedOk      bool
+	}{
+		{
+			name:            "Direct",
+			err:             notFound,
+			expectedDetails: &StatusDetails{Name: "tests", UID: "1"},
+			expectedOk:      true,
+		},
+		{
+			name:            "Wrapped",
+			err:             fmt.Errorf("wrapping: %w", notFound),
+			expectedDetails: &StatusDetails{Name: "tests", UID: "1"},
+			expectedOk:      true,
+		},
+		{
+			name:       "Without details",
+			err:        NewBadRequest("reason"),
+			expectedOk: false,
+		},
+		{
+			name:       "Non-API error",
+			err:        errors.New("some other error"),
+			expectedOk: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			details, ok := DetailsFromError(tc.err)
+			if ok != tc.expectedOk {
+				t.Fatalf("expected ok: %t, got: %t", tc.expectedOk, ok)
+			}
+			if !reflect.DeepEqual(tc.expectedDetails, details) {
+				t.Errorf("expected %#v, got %#v", tc.expectedDetails, details)
+			}
+		})
+	}
+}
+
+func TestStatusCauses(t *testing.T) {
+	err := NewInvalid("tests", field.ErrorList{
+		field.Required(field.NewPath("name"), ""),
+		field.Invalid(field.NewPath("port"), 0, "must be positive"),
+		field.Required(field.NewPath("image"), ""),
+	})
+	wrapped := fmt.Errorf("wrapping: %w", err)
+
+	if !HasAnyStatusCause(err, CauseTypeFieldValueDuplicate, CauseTypeFieldValueInvalid) {
+		t.Errorf("expected to have a %s cause", CauseTypeFieldValueInvalid)
+	}
+	if !HasAnyStatusCause(wrapped, CauseTypeFieldValueRequired) {
+		t.Errorf("expected to have a %s cause", CauseTypeFieldValueRequired)
+	}
+	if HasAnyStatusCause(err, CauseTypeFieldValueDuplicate, CauseTypeFieldValueNotFound) {
+		t.Errorf("expected to not have any of the causes")
+	}
+	if HasAnyStatusCause(err) {
+		t.Errorf("expected no match without cause types")
+	}
+	if HasAnyStatusCause(errors.New("some other error"), CauseTypeFieldValueRequired) {
+		t.Errorf("expected no match for a non-API error")
+	}
+
+	required := GetStatusCauses(wrapped, CauseTypeFieldValueRequired)
+	if len(required) != 2 || required[0].Field != "name" || required[1].Field != "image" {
+		t.Errorf("unexpected causes %#v", required)
+	}
+	if causes := GetStatusCauses(err, CauseTypeFieldValueDuplicate); causes != nil {
+		t.Errorf("expected no causes, got %#v", causes)
+	}
+	if causes := GetStatusCauses(errors.New("some other error"), CauseTypeFieldValueRequired); causes != nil {
+		t.Errorf("expected no causes, got %#v", causes)
+	}
+}
+
+func TestFromResponseStrict(t *testing.T) {
+	body := `{"status":"Failure","message":"tests not found","reason":"NotFound","code":404,"extra":true}`
+	newResponse := func() *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	err, hasError := FromResponse(newResponse())
+	if !hasError || !IsNotFound(err) {
+		t.Errorf("expected the lenient decoder to ignore the unknown field, got %v", err)
+	}
+
+	err, hasError = FromResponseStrict(newResponse())
+	if !hasError || !IsInternalError(err) {
+		t.Fatalf("expected the strict decoder to reject the unknown field, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `unknown field "extra"`) {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	resp := newResponse()
+	resp.Body = ioutil.NopCloser(strings.NewReader(`{"status":"Failure","message":"tests not found","reason":"NotFound","code":404}`))
+	if err, _ := FromResponseStrict(resp); !IsNotFound(err) {
+		t.Errorf("expected to be %s, got %v", StatusReasonNotFound, err)
+	}
+}
+
+func TestAsAPIError(t *testing.T) {
+	testCases := []struct {
+		name              string
+		err               error
+		expectOk          bool
+		expectedCode      int32
+		expectedReason    StatusReason
+		expectedMessage   string
+		expectedRetryable bool
+	}{
+		{
+			name:            "Direct",
+			err:             NewNotFound("tests", ""),
+			expectOk:        true,
+			expectedCode:    http.StatusNotFound,
+			expectedReason:  StatusReasonNotFound,
+			expectedMessage: "tests not found",
+		},
+		{
+			name:              "Wrapped",
+			err:               fmt.Errorf("wrapping: %w", NewServiceUnavailable("try later")),
+			expectOk:          true,
+			expectedCode:      http.StatusServiceUnavailable,
+			expectedReason:    StatusReasonServiceUnavailable,
+			expectedMessage:   "try later",
+			expectedRetryable: true,
+		},
+		{
+			name:     "Non-API error",
+			err:      errors.New("some other error"),
+			expectOk: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr, ok := AsAPIError(tc.err)
+			if ok != tc.expectOk {
+				t.Fatalf("expected ok: %t, got: %t", tc.expectOk, ok)
+			}
+			if !ok {
+				return
+			}
+			if apiErr.Code() != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, apiErr.Code())
+			}
+			if apiErr.Reason() != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, apiErr.Reason())
+			}
+			if apiErr.Message() != tc.expectedMessage {
+				t.Errorf("expected message %q, got %q", tc.expectedMessage, apiErr.Message())
+			}
+			if apiErr.Retryable() != tc.expectedRetryable {
+				t.Errorf("expected retryable %t, got %t", tc.expectedRetryable, apiErr.Retryable())
+			}
+		})
+	}
+}
+
+func TestStatusEnvelope(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status *Status
+	}{
+		{
+			name:   "Constructor",
+			status: ErrorToAPIStatus(NewNotFound("widget", "foo")),
+		},
+		{
+			name:   "Status without envelope",
+			status: ErrorToAPIStatus(&StatusError{ErrStatus: Status{Status: StatusFailure, Code: http.StatusConflict}}),
+		},
+		{
+			name:   "Plain error",
+			status: ErrorToAPIStatus(errors.New("boom")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := json.Marshal(tc.status)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var envelope map[string]interface{}
+			if err := json.Unmarshal(out, &envelope); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if envelope["apiVersion"] != StatusAPIVersion {
+				t.Errorf("expected apiVersion %q, got %v in %s", StatusAPIVersion, envelope["apiVersion"], out)
+			}
+			if envelope["kind"] != StatusKind {
+				t.Errorf("expected kind %q, got %v in %s", StatusKind, envelope["kind"], out)
+			}
+		})
+	}
+
+	out, err := json.Marshal(Status{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(out), `"apiVersion":""`) || !strings.Contains(string(out), `"kind":""`) {
+		t.Errorf("expected apiVersion and kind to always be marshaled, got %s", out)
+	}
+}
+
+func TestNewGenericServerResponseWithOptions(t *testing.T) {
+	opts := GenericServerResponseOptions{
+		Code:                 http.StatusConflict,
+		Verb:                 "POST",
+		Name:                 "widget",
+		ServerMessage:        "already exists",
+		RetryAfterSeconds:    5,
+		IsUnexpectedResponse: true,
+	}
+	expected := NewGenericServerResponse(http.StatusConflict, "POST", "widget", "already exists", 5, true)
+	if actual := NewGenericServerResponseWithOptions(opts); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+
+	expected = NewGenericServerResponse(http.StatusBadGateway, "", "", "", 0, false)
+	if actual := NewGenericServerResponseWithOptions(GenericServerResponseOptions{Code: http.StatusBadGateway}); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestFromResponseRetryAfterPrecedence(t *testing.T) {
+	testCases := []struct {
+		name            string
+		header          string
+		body            string
+		expectedSeconds int
+	}{
+		{
+			name:            "Header only",
+			header:          "5",
+			body:            `{"status":"Failure","reason":"TooManyRequests","code":429}`,
+			expectedSeconds: 5,
+		},
+		{
+			name:            "Body only",
+			body:            `{"status":"Failure","reason":"TooManyRequests","code":429,"details":{"retryAfterSeconds":7}}`,
+			expectedSeconds: 7,
+		},
+		{
+			name:            "Header longer than body",
+			header:          "10",
+			body:            `{"status":"Failure","reason":"TooManyRequests","code":429,"details":{"retryAfterSeconds":3}}`,
+			expectedSeconds: 10,
+		},
+		{
+			name:            "Body longer than header",
+			header:          "2",
+			body:            `{"status":"Failure","reason":"TooManyRequests","code":429,"details":{"retryAfterSeconds":8}}`,
+			expectedSeconds: 8,
+		},
+		{
+			name:            "Invalid header",
+			header:          "soon",
+			body:            `{"status":"Failure","reason":"TooManyRequests","code":429,"details":{"retryAfterSeconds":4}}`,
+			expectedSeconds: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(tc.body)),
+			}
+			if tc.header != "" {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			err, hasError := FromResponse(resp)
+			if !hasError {
+				t.Fatalf("expected an error")
+			}
+			if seconds, _ := SuggestsClientDelay(err); seconds != tc.expectedSeconds {
+				t.Errorf("expected %d seconds, got %d", tc.expectedSeconds, seconds)
+			}
+		})
+	}
+}
+
+func TestSuggestsClientDelayDefaultDelays(t *testing.T) {
+	defer func(saved map[StatusReason]int) { DefaultDelays = saved }(DefaultDelays)
+
+	DefaultDelays = nil
+	if seconds, ok := SuggestsClientDelay(NewTooManyRequests("slow down", 0)); seconds != 0 || ok {
+		t.Errorf("expected no delay without defaults, got %d, %v", seconds, ok)
+	}
+	if seconds, ok := SuggestsClientDelay(NewServiceUnavailable("down")); seconds != 0 || ok {
+		t.Errorf("expected no delay without defaults, got %d, %v", seconds, ok)
+	}
+
+	DefaultDelays = map[StatusReason]int{
+		StatusReasonTooManyRequests:    2,
+		StatusReasonServiceUnavailable: 5,
+	}
+	if seconds, ok := SuggestsClientDelay(NewTooManyRequests("slow down", 0)); seconds != 2 || !ok {
+		t.Errorf("expected the default delay, got %d, %v", seconds, ok)
+	}
+	if seconds, ok := SuggestsClientDelay(fmt.Errorf("wrapping: %w", NewServiceUnavailable("down"))); seconds != 5 || !ok {
+		t.Errorf("expected the default delay, got %d, %v", seconds, ok)
+	}
+	if seconds, ok := SuggestsClientDelay(NewTooManyRequests("slow down", 10)); seconds != 10 || !ok {
+		t.Errorf("expected the error's delay to take precedence, got %d, %v", seconds, ok)
+	}
+	if seconds, ok := SuggestsClientDelay(NewNotFound("tests", "")); seconds != 0 || ok {
+		t.Errorf("expected no delay for a reason without a default, got %d, %v", seconds, ok)
+	}
+}
+
+// roundTripStatus encodes status with StatusToJSON and decodes it again.
+func roundTripStatus(t *testing.T, status *Status) Status {
+	t.Helper()
+	out, err := StatusToJSON(status)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Status
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return decoded
+}
+
+func TestStatusUnmarshalCode(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int32
+		expectErr    bool
+	}{
+		{
+			name:         "Number",
+			body:         `{"status":"Failure","reason":"NotFound","code":404}`,
+			expectedCode: 404,
+		},
+		{
+			name:         "String",
+			body:         `{"status":"Failure","reason":"NotFound","code":"404"}`,
+			expectedCode: 404,
+		},
+		{
+			name:         "Null",
+			body:         `{"status":"Failure","reason":"NotFound","code":null}`,
+			expectedCode: 0,
+		},
+		{
+			name:         "Missing",
+			body:         `{"status":"Failure","reason":"NotFound"}`,
+			expectedCode: 0,
+		},
+		{
+			name:      "Invalid string",
+			body:      `{"status":"Failure","reason":"NotFound","code":"not found"}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var status Status
+			err := json.Unmarshal([]byte(tc.body), &status)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status.Code != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, status.Code)
+			}
+			if status.Reason != StatusReasonNotFound {
+				t.Errorf("expected reason %q, got %q", StatusReasonNotFound, status.Reason)
+			}
+		})
+	}
+
+	for _, body := range []string{
+		`{"status":"Failure","message":"tests not found","reason":"NotFound","code":404}`,
+		`{"status":"Failure","message":"tests not found","reason":"NotFound","code":"404"}`,
+	} {
+		for _, fromResponse := range []func(*http.Response) (error, bool){FromResponse, FromResponseStrict} {
+			resp := &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}
+			err, _ := fromResponse(resp)
+			if code := CodeForError(err); code != http.StatusNotFound {
+				t.Errorf("%s: expected code %d, got %d (%v)", body, http.StatusNotFound, code, err)
+			}
+		}
+	}
+
+	status := ErrorToAPIStatus(NewConflict("tests", errors.New("conflict")))
+	if decoded := roundTripStatus(t, status); !reflect.DeepEqual(*status, decoded) {
+		t.Errorf("expected %#v, got %#v", *status, decoded)
+	}
+}
+
+func TestParseStatusJSON(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      string
+		expectErr bool
+	}{
+		{name: "Status", data: `{"status":"Failure","reason":"NotFound","code":404}`},
+		{name: "Whitespace", data: " \n{\"reason\":\"NotFound\"}\n"},
+		{name: "Empty", data: ``, expectErr: true},
+		{name: "Null", data: `null`, expectErr: true},
+		{name: "Array", data: `[{"reason":"NotFound"}]`, expectErr: true},
+		{name: "Truncated", data: `{"status":"Failure","reason":"NotF`, expectErr: true},
+		{name: "Wrong type", data: `{"details":"not an object"}`, expectErr: true},
+		{name: "Deeply nested", data: `{"details":` + strings.Repeat("[", 100000) + strings.Repeat("]", 100000) + `}`, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, err := ParseStatusJSON([]byte(tc.data))
+			if tc.expectErr {
+				if err == nil || status != nil {
+					t.Errorf("expected an error, got %#v", status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status.Reason != StatusReasonNotFound {
+				t.Errorf("expected reason %q, got %q", StatusReasonNotFound, status.Reason)
+			}
+		})
+	}
+}
+
+func FuzzParseStatusJSON(f *testing.F) {
+	f.Add([]byte(`{"status":"Failure","message":"tests not found","reason":"NotFound","code":404}`))
+	f.Add([]byte(`{"status":"Failure","reason":"NotFound","code":"404","details":{"causes":[{"reason":"FieldValueInvalid"}]}}`))
+	// regression: truncated body from a broken proxy
+	f.Add([]byte(`{"status":"Failure","details":{"causes":[{"reason":`))
+	f.Add([]byte(`{"code":"`))
+	f.Add([]byte(`null`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		status, err := ParseStatusJSON(data)
+		if (err == nil) == (status == nil) {
+			t.Fatalf("expected exactly one of a status or an error, got %#v, %v", status, err)
+		}
+	})
+}
+
+func TestStatusString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   Status
+		expected string
+	}{
+		{
+			name:     "NotFound",
+			status:   NewNotFound("tests", "").ErrStatus,
+			expected: `Status(404 NotFound: "tests not found")`,
+		},
+		{
+			name:     "Without reason",
+			status:   Status{Code: http.StatusBadGateway, Message: `upstream said "no"`},
+			expected: `Status(502: "upstream said \"no\"")`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.status.String(); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+			if actual := fmt.Sprint(tc.status); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVerboseErrors(t *testing.T) {
+	defer func(saved bool) { VerboseErrors = saved }(VerboseErrors)
+
+	err := NewNotFound("tests", "")
+	VerboseErrors = false
+	if err.Error() != "tests not found" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	VerboseErrors = true
+	if expected := `Status(404 NotFound: "tests not found")`; err.Error() != expected {
+		t.Errorf("expected %s, got %s", expected, err.Error())
+	}
+}
+
+func TestNewTooManyRequestsWithReason(t *testing.T) {
+	testCases := []struct {
+		name              string
+		err               error
+		expectedSubReason string
+		expectOk          bool
+		expectRetryable   bool
+	}{
+		{
+			name:              "Rate limited",
+			err:               NewTooManyRequestsWithReason("slow down", 1, TooManyRequestsRateLimited),
+			expectedSubReason: TooManyRequestsRateLimited,
+			expectOk:          true,
+			expectRetryable:   true,
+		},
+		{
+			name:              "Quota exceeded",
+			err:               fmt.Errorf("wrapping: %w", NewTooManyRequestsWithReason("quota exhausted", 0, TooManyRequestsQuotaExceeded)),
+			expectedSubReason: TooManyRequestsQuotaExceeded,
+			expectOk:          true,
+			expectRetryable:   false,
+		},
+		{
+			name:            "Without sub-reason",
+			err:             NewTooManyRequests("slow down", 1),
+			expectRetryable: true,
+		},
+		{
+			name: "Other reason",
+			err:  NewNotFound("tests", ""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subReason, ok := TooManyRequestsSubReason(tc.err)
+			if subReason != tc.expectedSubReason || ok != tc.expectOk {
+				t.Errorf("expected %q, %v, got %q, %v", tc.expectedSubReason, tc.expectOk, subReason, ok)
+			}
+			if IsRetryable(tc.err) != tc.expectRetryable {
+				t.Errorf("expected retryable to be %v", tc.expectRetryable)
+			}
+		})
+	}
+
+	err := NewTooManyRequestsWithReason("slow down", 7, TooManyRequestsRateLimited)
+	if !IsTooManyRequests(err) || err.ErrStatus.Code != http.StatusTooManyRequests {
+		t.Errorf("expected to be %s, got %#v", StatusReasonTooManyRequests, err.ErrStatus)
+	}
+	if seconds, ok := SuggestsClientDelay(err); seconds != 7 || !ok {
+		t.Errorf("expected a delay of 7 seconds, got %d, %v", seconds, ok)
+	}
+}
+
+type fakeNetError struct {
+	timeout   bool
+	temporary bool
+}
+
+func (e fakeNetError) Error() string   { return "network failure" }
+func (e fakeNetError) Timeout() bool   { return e.timeout }
+func (e fakeNetError) Temporary() bool { return e.temporary }
+
+func TestErrorToAPIStatusNetworkErrors(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedReason StatusReason
+		expectedCode   int32
+	}{
+		{"Timeout", fakeNetError{timeout: true, temporary: true}, StatusReasonTimeout, http.StatusGatewayTimeout},
+		{"Temporary", fakeNetError{temporary: true}, StatusReasonServerTimeout, http.StatusInternalServerError},
+		{"Wrapped timeout", fmt.Errorf("dialing: %w", fakeNetError{timeout: true}), StatusReasonTimeout, http.StatusGatewayTimeout},
+		{"Neither", fakeNetError{}, StatusReasonUnknown, http.StatusInternalServerError},
+		{"Plain", errors.New("boom"), StatusReasonUnknown, http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := ErrorToAPIStatus(tc.err)
+			if status.Reason != tc.expectedReason || status.Code != tc.expectedCode {
+				t.Errorf("expected %s %d, got %s %d", tc.expectedReason, tc.expectedCode, status.Reason, status.Code)
+			}
+			if status.Message != tc.err.Error() {
+				t.Errorf("expected message %q, got %q", tc.err.Error(), status.Message)
+			}
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	nameErr := field.Required(field.NewPath("spec", "name"), "")
+	sizeErr := field.Invalid(field.NewPath("spec", "size"), -1, "must be positive")
+	sizeErr2 := field.Invalid(field.NewPath("spec", "size"), -1, "must be even")
+	err := fmt.Errorf("wrapping: %w", NewInvalid("tests", field.ErrorList{nameErr, sizeErr, sizeErr2}))
+
+	expected := map[string]string{
+		"spec.name": nameErr.ErrorBody(),
+		"spec.size": sizeErr.ErrorBody() + "; " + sizeErr2.ErrorBody(),
+	}
+	if actual := FieldErrors(err); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+
+	fieldErrors := map[string]string{"spec.name": "must not be empty", "metadata.a": "must be lowercase"}
+	if actual := FieldErrors(NewInvalidFromMap("tests", fieldErrors)); !reflect.DeepEqual(fieldErrors, actual) {
+		t.Errorf("expected %#v, got %#v", fieldErrors, actual)
+	}
+
+	withoutField := NewInvalidFromCauses("tests", []StatusCause{{Type: CauseTypeFieldValueInvalid, Message: "no field"}})
+	if actual := FieldErrors(withoutField); actual != nil {
+		t.Errorf("expected causes without a field to be skipped, got %#v", actual)
+	}
+	if actual := FieldErrors(errors.New("boom")); actual != nil {
+		t.Errorf("expected nil, got %#v", actual)
+	}
+}
@@ -0,0 +1,34 @@
+// Package errorstest provides helpers for asserting the reason and code of API
+// errors in tests.
+package errorstest
+
+import (
+	"github.com/clarkmcc/apiutils/errors"
+	"testing"
+)
+
+// RequireReason fails the test immediately if err does not have the provided
+// reason. It supports wrapped errors.
+func RequireReason(t testing.TB, err error, reason errors.StatusReason) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected an error with reason %q, got nil", reason)
+		return
+	}
+	if actual := errors.ReasonForError(err); actual != reason {
+		t.Fatalf("expected an error with reason %q, got reason %q (code %d): %v", reason, actual, errors.CodeForError(err), err)
+	}
+}
+
+// RequireCode fails the test immediately if err does not have the provided
+// HTTP status code. It supports wrapped errors.
+func RequireCode(t testing.TB, err error, code int32) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected an error with code %d, got nil", code)
+		return
+	}
+	if actual := errors.CodeForError(err); actual != code {
+		t.Fatalf("expected an error with code %d, got code %d (reason %q): %v", code, actual, errors.ReasonForError(err), err)
+	}
+}
@@ -0,0 +1,78 @@
+package errorstest
+
+import (
+	"fmt"
+	"github.com/clarkmcc/apiutils/errors"
+	"net/http"
+	"testing"
+)
+
+// recorder is a testing.TB that records the failure message instead of
+// failing the test.
+type recorder struct {
+	testing.TB
+	failed  bool
+	message string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failed = true
+	r.message = fmt.Sprintf(format, args...)
+}
+
+func TestRequireReason(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		reason   errors.StatusReason
+		expected string
+	}{
+		{"Match", errors.NewNotFound("tests", ""), errors.StatusReasonNotFound, ""},
+		{"Wrapped", fmt.Errorf("wrapped: %w", errors.NewNotFound("tests", "")), errors.StatusReasonNotFound, ""},
+		{"Mismatch", errors.NewConflict("tests", fmt.Errorf("boom")), errors.StatusReasonNotFound, `expected an error with reason "NotFound", got reason "Conflict" (code 409): Operation cannot be fulfilled on tests: boom`},
+		{"Nil", nil, errors.StatusReasonNotFound, `expected an error with reason "NotFound", got nil`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{}
+			RequireReason(r, tc.err, tc.reason)
+			if r.failed != (len(tc.expected) > 0) {
+				t.Fatalf("expected failed to be %t, got %t", len(tc.expected) > 0, r.failed)
+			}
+			if r.message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, r.message)
+			}
+		})
+	}
+}
+
+func TestRequireCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		code     int32
+		expected string
+	}{
+		{"Match", errors.NewNotFound("tests", ""), http.StatusNotFound, ""},
+		{"Wrapped", fmt.Errorf("wrapped: %w", errors.NewNotFound("tests", "")), http.StatusNotFound, ""},
+		{"Mismatch", errors.NewBadRequest("bad"), http.StatusNotFound, `expected an error with code 404, got code 400 (reason "BadRequest"): bad`},
+		{"NotAPIError", fmt.Errorf("boom"), http.StatusNotFound, `expected an error with code 404, got code 0 (reason ""): boom`},
+		{"Nil", nil, http.StatusNotFound, "expected an error with code 404, got nil"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{}
+			RequireCode(r, tc.err, tc.code)
+			if r.failed != (len(tc.expected) > 0) {
+				t.Fatalf("expected failed to be %t, got %t", len(tc.expected) > 0, r.failed)
+			}
+			if r.message != tc.expected {
+				t.Errorf("expected message %q, got %q", tc.expected, r.message)
+			}
+		})
+	}
+}
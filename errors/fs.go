@@ -0,0 +1,27 @@
+package errors
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// FromFSError converts an error returned by the os or io/fs packages, such as
+// a *fs.PathError or *os.LinkError, into a StatusError: fs.ErrNotExist becomes
+// not found, fs.ErrPermission becomes forbidden and any other error becomes an
+// internal error. The paths of the files are not included in the not found and
+// forbidden messages, but err is retained as the cause of the returned error.
+// Returns nil when err is nil. It supports wrapped errors.
+func FromFSError(err error) *StatusError {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, fs.ErrNotExist):
+		out := NewNotFound("file", "")
+		out.cause = err
+		return out
+	case errors.Is(err, fs.ErrPermission):
+		return NewForbiddenWithCause("file", "permission denied", err)
+	default:
+		return NewInternalError(err)
+	}
+}
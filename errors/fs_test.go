@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFromFSError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedReason StatusReason
+		expectedCode   int32
+	}{
+		{"PathNotExist", &fs.PathError{Op: "open", Path: "/srv/secret.txt", Err: fs.ErrNotExist}, StatusReasonNotFound, http.StatusNotFound},
+		{"PathPermission", &fs.PathError{Op: "open", Path: "/srv/secret.txt", Err: fs.ErrPermission}, StatusReasonForbidden, http.StatusForbidden},
+		{"LinkNotExist", &os.LinkError{Op: "symlink", Old: "/srv/a", New: "/srv/secret.txt", Err: fs.ErrNotExist}, StatusReasonNotFound, http.StatusNotFound},
+		{"LinkPermission", &os.LinkError{Op: "symlink", Old: "/srv/a", New: "/srv/secret.txt", Err: fs.ErrPermission}, StatusReasonForbidden, http.StatusForbidden},
+		{"Wrapped", fmt.Errorf("serving: %w", &fs.PathError{Op: "stat", Path: "/srv/secret.txt", Err: fs.ErrNotExist}), StatusReasonNotFound, http.StatusNotFound},
+		{"Other", &fs.PathError{Op: "read", Path: "/srv/secret.txt", Err: errors.New("i/o error")}, StatusReasonInternalError, http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			statusErr := FromFSError(tc.err)
+			if statusErr == nil {
+				t.Fatal("expected an error")
+			}
+			if reason := ReasonForError(statusErr); reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+			if statusErr.Code() != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, statusErr.Code())
+			}
+			if !errors.Is(statusErr, tc.err) {
+				t.Errorf("expected the error to wrap %v", tc.err)
+			}
+			if tc.expectedReason != StatusReasonInternalError && strings.Contains(statusErr.Error(), "/srv/secret.txt") {
+				t.Errorf("expected the message not to contain the path, got %q", statusErr.Error())
+			}
+		})
+	}
+
+	if FromFSError(nil) != nil {
+		t.Errorf("expected nil")
+	}
+}
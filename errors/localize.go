@@ -0,0 +1,66 @@
+package errors
+
+import "fmt"
+
+// MessageLocalizer translates the human-readable messages produced by the error
+// constructors. The reason identifies the kind of error, while format and args
+// are the English format string and arguments that the constructor would have
+// used. Only the Message of a Status is localized, the Reason and Code are left
+// untouched so that machine clients are unaffected.
+type MessageLocalizer interface {
+	Localize(reason StatusReason, format string, args ...interface{}) string
+}
+
+// localizer is the MessageLocalizer consulted by the error constructors. When
+// nil, the English messages are used.
+var localizer MessageLocalizer
+
+// SetLocalizer sets the MessageLocalizer consulted by the error constructors.
+// Passing nil restores the default English messages. It is not safe to call
+// SetLocalizer concurrently with the constructors and it is intended to be
+// called once during program initialization.
+func SetLocalizer(l MessageLocalizer) {
+	localizer = l
+}
+
+// localize returns the message for the provided reason, format and args using
+// the registered MessageLocalizer, or the English message if none is registered.
+func localize(reason StatusReason, format string, args ...interface{}) string {
+	if localizer == nil {
+		return fmt.Sprintf(format, args...)
+	}
+	return localizer.Localize(reason, format, args...)
+}
+
+// LocaleLocalizer is implemented by MessageLocalizers that can translate the
+// message of an existing Status into one of several locales, so that messages
+// can be localized per request rather than once at construction.
+type LocaleLocalizer interface {
+	MessageLocalizer
+	// Locales returns the supported locales as language tags, e.g. "en" or
+	// "de-CH". The first locale is the default.
+	Locales() []string
+	// LocalizeStatus returns the message of the status translated into the
+	// locale, which is one of Locales.
+	LocalizeStatus(locale string, status Status) string
+}
+
+// Locales returns the locales supported by the registered MessageLocalizer,
+// or nil if it is not a LocaleLocalizer.
+func Locales() []string {
+	if l, ok := localizer.(LocaleLocalizer); ok {
+		return l.Locales()
+	}
+	return nil
+}
+
+// LocalizeStatus returns a copy of the status with its message translated into
+// the locale by the registered MessageLocalizer. The status is returned
+// unchanged if the registered MessageLocalizer is not a LocaleLocalizer. The
+// Reason and Code are never changed.
+func LocalizeStatus(status Status, locale string) Status {
+	if l, ok := localizer.(LocaleLocalizer); ok {
+		status.Message = l.LocalizeStatus(locale, status)
+	}
+	return status
+}
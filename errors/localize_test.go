@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type upperLocalizer struct{}
+
+func (upperLocalizer) Localize(reason StatusReason, format string, args ...interface{}) string {
+	return strings.ToUpper(fmt.Sprintf(format, args...))
+}
+
+func TestSetLocalizer(t *testing.T) {
+	SetLocalizer(upperLocalizer{})
+	defer SetLocalizer(nil)
+
+	testCases := []struct {
+		err             *StatusError
+		expectedMessage string
+		expectedReason  StatusReason
+		expectedCode    int32
+	}{
+		{NewNotFound("tests", "1"), "TESTS (1) NOT FOUND", StatusReasonNotFound, http.StatusNotFound},
+		{NewForbidden("tests", errors.New("reason")), "FORBIDDEN: REASON", StatusReasonForbidden, http.StatusForbidden},
+		{NewUnauthorized(""), "NOT AUTHORIZED", StatusReasonUnauthorized, http.StatusUnauthorized},
+		{NewBadRequest("bad"), "BAD", StatusReasonBadRequest, http.StatusBadRequest},
+	}
+	for i, tc := range testCases {
+		status := tc.err.ErrStatus
+		if status.Message != tc.expectedMessage {
+			t.Errorf("%d: expected message %q, got %q", i, tc.expectedMessage, status.Message)
+		}
+		if status.Reason != tc.expectedReason || status.Code != tc.expectedCode {
+			t.Errorf("%d: unexpected reason or code: %#v", i, status)
+		}
+	}
+
+	SetLocalizer(nil)
+	if message := NewNotFound("tests", "").ErrStatus.Message; message != "tests not found" {
+		t.Errorf("expected the default message, got %q", message)
+	}
+}
+
+type localeLocalizer struct{}
+
+func (localeLocalizer) Localize(reason StatusReason, format string, args ...interface{}) string {
+	return fmt.Sprintf(format, args...)
+}
+
+func (localeLocalizer) Locales() []string {
+	return []string{"en", "de"}
+}
+
+func (localeLocalizer) LocalizeStatus(locale string, status Status) string {
+	if locale == "de" && status.Reason == StatusReasonNotFound {
+		return fmt.Sprintf("%s nicht gefunden", status.Details.Name)
+	}
+	return status.Message
+}
+
+func TestLocalizeStatus(t *testing.T) {
+	status := NewNotFound("tests", "").ErrStatus
+	if Locales() != nil {
+		t.Errorf("expected no locales without a localizer, got %v", Locales())
+	}
+	if localized := LocalizeStatus(status, "de"); localized.Message != "tests not found" {
+		t.Errorf("expected the message to be unchanged, got %q", localized.Message)
+	}
+
+	SetLocalizer(localeLocalizer{})
+	defer SetLocalizer(nil)
+
+	if locales := Locales(); len(locales) != 2 || locales[0] != "en" {
+		t.Errorf("unexpected locales %v", locales)
+	}
+	localized := LocalizeStatus(status, "de")
+	if localized.Message != "tests nicht gefunden" {
+		t.Errorf("unexpected message %q", localized.Message)
+	}
+	if localized.Reason != status.Reason || localized.Code != status.Code {
+		t.Errorf("expected the reason and code to be unchanged, got %#v", localized)
+	}
+	if status.Message != "tests not found" {
+		t.Errorf("expected the original status to be unmodified, got %q", status.Message)
+	}
+}
@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// openAPISchemaTypes are the types described by StatusOpenAPISchema, keyed by
+// the names of their component schemas.
+var openAPISchemaTypes = map[string]reflect.Type{
+	"Status":        reflect.TypeOf(Status{}),
+	"StatusDetails": reflect.TypeOf(StatusDetails{}),
+	"StatusCause":   reflect.TypeOf(StatusCause{}),
+}
+
+// StatusOpenAPISchema returns the OpenAPI component schemas of the Status,
+// StatusDetails and StatusCause types keyed by their names, e.g. to embed them
+// into the components.schemas section of a spec. The schemas are generated
+// from the JSON encoding of the types, so they always match what is written to
+// clients. Properties without omitempty are required, and StatusDetails and
+// StatusCause are referenced as "#/components/schemas/<name>".
+func StatusOpenAPISchema() map[string]interface{} {
+	out := make(map[string]interface{}, len(openAPISchemaTypes))
+	for name, t := range openAPISchemaTypes {
+		out[name] = openAPIObjectSchema(t)
+	}
+	return out
+}
+
+// openAPIObjectSchema returns the schema of a struct type from its JSON tags.
+func openAPIObjectSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name, omitempty := jsonFieldName(f)
+		if name == "-" {
+			continue
+		}
+		properties[name] = openAPITypeSchema(f.Type)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// openAPITypeSchema returns the schema of a field type, referencing the other
+// component schemas by name.
+func openAPITypeSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for name, schemaType := range openAPISchemaTypes {
+		if t == schemaType {
+			return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+		}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": openAPITypeSchema(t.Elem())}
+	}
+	panic(fmt.Sprintf("errors: no OpenAPI schema for type %s", t))
+}
+
+// jsonFieldName returns the JSON name of the field and whether it is omitted
+// when empty.
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	tag := strings.Split(f.Tag.Get("json"), ",")
+	name := tag[0]
+	if len(name) == 0 {
+		name = f.Name
+	}
+	for _, option := range tag[1:] {
+		if option == "omitempty" {
+			return name, true
+		}
+	}
+	return name, false
+}
@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStatusOpenAPISchema(t *testing.T) {
+	schemas := StatusOpenAPISchema()
+	for name, value := range map[string]interface{}{"Status": Status{}, "StatusDetails": StatusDetails{}, "StatusCause": StatusCause{}} {
+		schema, ok := schemas[name].(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s: missing schema", name)
+		}
+		properties := schema["properties"].(map[string]interface{})
+		typ := reflect.TypeOf(value)
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			property, _ := jsonFieldName(f)
+			if _, ok := properties[property]; !ok {
+				t.Errorf("%s: missing property %q for field %s", typ.Name(), property, f.Name)
+			}
+		}
+		if len(properties) != typ.NumField() {
+			t.Errorf("%s: expected %d properties, got %d", typ.Name(), typ.NumField(), len(properties))
+		}
+	}
+
+	status := schemas["Status"].(map[string]interface{})
+	if !reflect.DeepEqual([]string{"apiVersion", "kind"}, status["required"]) {
+		t.Errorf("unexpected required properties %v", status["required"])
+	}
+	properties := status["properties"].(map[string]interface{})
+	expected := map[string]interface{}{"$ref": "#/components/schemas/StatusDetails"}
+	if !reflect.DeepEqual(expected, properties["details"]) {
+		t.Errorf("expected details %v, got %v", expected, properties["details"])
+	}
+	expected = map[string]interface{}{"type": "integer", "format": "int32"}
+	if !reflect.DeepEqual(expected, properties["code"]) {
+		t.Errorf("expected code %v, got %v", expected, properties["code"])
+	}
+	details := schemas["StatusDetails"].(map[string]interface{})["properties"].(map[string]interface{})
+	expected = map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/StatusCause"}}
+	if !reflect.DeepEqual(expected, details["causes"]) {
+		t.Errorf("expected causes %v, got %v", expected, details["causes"])
+	}
+
+	if _, err := json.Marshal(schemas); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitInfo describes the rate limit of a server as reported by the
+// X-RateLimit-* headers of a response.
+type RateLimitInfo struct {
+	// Limit is the number of requests allowed in the current window, or -1 if
+	// the X-RateLimit-Limit header is missing.
+	Limit int
+	// Remaining is the number of requests left in the current window, or -1 if
+	// the X-RateLimit-Remaining header is missing.
+	Remaining int
+	// Reset is the time at which the current window ends, or the zero time if
+	// the X-RateLimit-Reset header is missing.
+	Reset time.Time
+}
+
+// unixResetThreshold separates the two forms of the X-RateLimit-Reset header:
+// larger values are Unix timestamps, smaller values are delta seconds.
+const unixResetThreshold = 1000000000
+
+// RateLimitInfoFromResponse parses the X-RateLimit-Limit, X-RateLimit-Remaining
+// and X-RateLimit-Reset headers of the response. The reset header may contain
+// either a Unix timestamp or a number of seconds from now. Returns false if
+// none of the headers are present and valid.
+func RateLimitInfoFromResponse(resp *http.Response) (RateLimitInfo, bool) {
+	info := RateLimitInfo{Limit: -1, Remaining: -1}
+	found := false
+	if i, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil && i >= 0 {
+		info.Limit, found = i, true
+	}
+	if i, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil && i >= 0 {
+		info.Remaining, found = i, true
+	}
+	if i, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && i >= 0 {
+		if i >= unixResetThreshold {
+			info.Reset = time.Unix(i, 0)
+		} else {
+			info.Reset = now().Add(time.Duration(i) * time.Second)
+		}
+		found = true
+	}
+	return info, found
+}
+
+// retryAfterSeconds returns the number of whole seconds until the rate limit
+// resets, rounded up, and true, or 0 and false if the reset time is unknown.
+func (i RateLimitInfo) retryAfterSeconds() (int, bool) {
+	if i.Reset.IsZero() {
+		return 0, false
+	}
+	seconds := int(math.Ceil(i.Reset.Sub(now()).Seconds()))
+	if seconds < 0 {
+		seconds = 0
+	}
+	return seconds, true
+}
@@ -0,0 +1,118 @@
+package errors
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitInfoFromResponse(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	current := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+
+	testCases := []struct {
+		name       string
+		headers    map[string]string
+		expected   RateLimitInfo
+		expectedOk bool
+	}{
+		{
+			name:       "Unix timestamp",
+			headers:    map[string]string{"X-RateLimit-Limit": "60", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1445412510"},
+			expected:   RateLimitInfo{Limit: 60, Remaining: 0, Reset: time.Unix(1445412510, 0)},
+			expectedOk: true,
+		},
+		{
+			name:       "Delta seconds",
+			headers:    map[string]string{"X-RateLimit-Remaining": "3", "X-RateLimit-Reset": "45"},
+			expected:   RateLimitInfo{Limit: -1, Remaining: 3, Reset: current.Add(45 * time.Second)},
+			expectedOk: true,
+		},
+		{
+			name:       "Malformed",
+			headers:    map[string]string{"X-RateLimit-Remaining": "many", "X-RateLimit-Reset": "soon"},
+			expected:   RateLimitInfo{Limit: -1, Remaining: -1},
+			expectedOk: false,
+		},
+		{
+			name:       "Missing",
+			expected:   RateLimitInfo{Limit: -1, Remaining: -1},
+			expectedOk: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			for k, v := range tc.headers {
+				resp.Header.Set(k, v)
+			}
+			info, ok := RateLimitInfoFromResponse(resp)
+			if ok != tc.expectedOk {
+				t.Errorf("expected ok to be %t, got %t", tc.expectedOk, ok)
+			}
+			if !reflect.DeepEqual(tc.expected, info) {
+				t.Errorf("expected %#v, got %#v", tc.expected, info)
+			}
+		})
+	}
+}
+
+func TestFromResponseRateLimitReset(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC) }
+
+	testCases := []struct {
+		name            string
+		code            int
+		headers         map[string]string
+		expectedSeconds int
+		expectedOk      bool
+	}{
+		{
+			name:            "Reset",
+			code:            http.StatusTooManyRequests,
+			headers:         map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1445412510"},
+			expectedSeconds: 30,
+			expectedOk:      true,
+		},
+		{
+			name:            "Retry-After takes precedence",
+			code:            http.StatusTooManyRequests,
+			headers:         map[string]string{"Retry-After": "5", "X-RateLimit-Reset": "1445412510"},
+			expectedSeconds: 5,
+			expectedOk:      true,
+		},
+		{
+			name:            "Not rate limited",
+			code:            http.StatusBadRequest,
+			headers:         map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1445412510"},
+			expectedSeconds: 0,
+			expectedOk:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tc.code,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+			for k, v := range tc.headers {
+				resp.Header.Set(k, v)
+			}
+			err, hasError := FromResponse(resp)
+			if !hasError {
+				t.Fatalf("expected an error")
+			}
+			if seconds, ok := SuggestsClientDelay(err); seconds != tc.expectedSeconds || ok != tc.expectedOk {
+				t.Errorf("expected (%d, %t), got (%d, %t)", tc.expectedSeconds, tc.expectedOk, seconds, ok)
+			}
+		})
+	}
+}
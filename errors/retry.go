@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultRetryBackoff is the delay used between attempts when the returned
+// error does not suggest a client delay. It is doubled after every attempt.
+var DefaultRetryBackoff = 100 * time.Millisecond
+
+// RetryOptions configures the behavior of RetryWithDelay.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of times the function is called. Values
+	// less than one are treated as one.
+	MaxAttempts int
+	// Backoff is the initial delay between attempts when the error does not
+	// suggest a client delay. It is doubled after every attempt and defaults to
+	// DefaultRetryBackoff.
+	Backoff time.Duration
+	// Retryable determines whether an error should be retried and defaults to
+	// IsRetryable.
+	Retryable func(error) bool
+}
+
+// RetryOnConflict calls fn until it succeeds, returns an error that is not a
+// conflict, the context is done, or maxAttempts is reached.
+func RetryOnConflict(ctx context.Context, maxAttempts int, fn func() error) error {
+	return RetryWithDelay(ctx, fn, RetryOptions{
+		MaxAttempts: maxAttempts,
+		Retryable:   IsConflict,
+	})
+}
+
+// RetryWithDelay calls fn until it succeeds, returns a non-retryable error, the
+// context is done, or the maximum number of attempts is reached. Between
+// attempts it sleeps for the delay suggested by SuggestsClientDelay, falling
+// back to an exponential backoff when no delay is suggested. The last error
+// returned by fn is returned when the attempts are exhausted, and the context
+// error is returned if the context is done while waiting. It never sleeps past
+// the context deadline: if the delay would exceed it, the last error is
+// returned wrapped immediately.
+func RetryWithDelay(ctx context.Context, fn func() error, opts RetryOptions) error {
+	retryable := opts.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= opts.MaxAttempts || !retryable(err) {
+			return err
+		}
+		delay := backoff
+		if seconds, ok := SuggestsClientDelay(err); ok && seconds > 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else {
+			backoff *= 2
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return fmt.Errorf("retry delay of %v exceeds the context deadline: %w", delay, err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
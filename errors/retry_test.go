@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func failTimes(n int, err error) (func() error, *int) {
+	calls := 0
+	return func() error {
+		calls++
+		if calls <= n {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	defer func(backoff time.Duration) { DefaultRetryBackoff = backoff }(DefaultRetryBackoff)
+	DefaultRetryBackoff = time.Millisecond
+
+	fn, calls := failTimes(2, NewConflict("tests", errors.New("message")))
+	if err := RetryOnConflict(context.Background(), 5, fn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 calls, got %d", *calls)
+	}
+
+	fn, calls = failTimes(5, NewConflict("tests", errors.New("message")))
+	if err := RetryOnConflict(context.Background(), 3, fn); !IsConflict(err) {
+		t.Errorf("expected to be %s, got %v", StatusReasonConflict, err)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 calls, got %d", *calls)
+	}
+
+	fn, calls = failTimes(2, NewNotFound("tests", ""))
+	if err := RetryOnConflict(context.Background(), 5, fn); !IsNotFound(err) {
+		t.Errorf("expected to be %s, got %v", StatusReasonNotFound, err)
+	}
+	if *calls != 1 {
+		t.Errorf("expected 1 call, got %d", *calls)
+	}
+}
+
+func TestRetryWithDelay(t *testing.T) {
+	opts := RetryOptions{MaxAttempts: 5, Backoff: time.Millisecond}
+
+	fn, calls := failTimes(3, NewServerTimeout("tests", 0))
+	if err := RetryWithDelay(context.Background(), fn, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *calls != 4 {
+		t.Errorf("expected 4 calls, got %d", *calls)
+	}
+
+	fn, calls = failTimes(3, NewConflict("tests", errors.New("message")))
+	if err := RetryWithDelay(context.Background(), fn, opts); !IsConflict(err) {
+		t.Errorf("expected to be %s, got %v", StatusReasonConflict, err)
+	}
+	if *calls != 1 {
+		t.Errorf("expected 1 call, got %d", *calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fn, calls = failTimes(3, NewServerTimeout("tests", 10))
+	if err := RetryWithDelay(ctx, fn, opts); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if *calls != 1 {
+		t.Errorf("expected 1 call, got %d", *calls)
+	}
+}
+
+func TestRetryWithDelayDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	fn, calls := failTimes(5, NewTooManyRequests("slow down", 60))
+	start := time.Now()
+	err := RetryWithDelay(ctx, fn, RetryOptions{MaxAttempts: 5})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to return before the suggested delay, took %v", elapsed)
+	}
+	if !IsTooManyRequests(err) {
+		t.Errorf("expected the wrapped last error, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("expected 1 call, got %d", *calls)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	fn, calls = failTimes(2, NewServerTimeout("tests", 0))
+	if err := RetryWithDelay(ctx, fn, RetryOptions{MaxAttempts: 5, Backoff: time.Millisecond}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 calls, got %d", *calls)
+	}
+}
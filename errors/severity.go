@@ -0,0 +1,55 @@
+package errors
+
+import "net/http"
+
+// Severity classifies errors by whether the client or the server is at fault,
+// e.g. to route server errors to on-call while ignoring client errors.
+type Severity int
+
+const (
+	// SeverityUnknown is used for errors without a 4xx or 5xx status code.
+	SeverityUnknown Severity = iota
+	// SeverityClient is used for errors with a 4xx status code.
+	SeverityClient
+	// SeverityServer is used for errors with a 5xx status code.
+	SeverityServer
+)
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityClient:
+		return "Client"
+	case SeverityServer:
+		return "Server"
+	}
+	return "Unknown"
+}
+
+// SeverityForError returns the severity of the error derived from its status
+// code, or SeverityUnknown if it has no 4xx or 5xx status code.
+// It supports wrapped errors.
+func SeverityForError(err error) Severity {
+	code := CodeForError(err)
+	switch {
+	case code >= http.StatusBadRequest && code < http.StatusInternalServerError:
+		return SeverityClient
+	case code >= http.StatusInternalServerError && code < 600:
+		return SeverityServer
+	}
+	return SeverityUnknown
+}
+
+// IsClientError determines if err is an error which indicates a 4xx status
+// code, i.e. one where the client is at fault.
+// It supports wrapped errors.
+func IsClientError(err error) bool {
+	return SeverityForError(err) == SeverityClient
+}
+
+// IsServerError determines if err is an error which indicates a 5xx status
+// code, i.e. one where the server is at fault.
+// It supports wrapped errors.
+func IsServerError(err error) bool {
+	return SeverityForError(err) == SeverityServer
+}
@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSeverityForError(t *testing.T) {
+	testCases := []struct {
+		name             string
+		err              error
+		expectedSeverity Severity
+	}{
+		{"NotFound", NewNotFound("tests", ""), SeverityClient},
+		{"Invalid", NewInvalidFromMap("tests", map[string]string{"a": "b"}), SeverityClient},
+		{"Forbidden", NewForbidden("tests", errors.New("denied")), SeverityClient},
+		{"TooManyRequests", NewTooManyRequests("slow down", 1), SeverityClient},
+		{"InternalError", NewInternalError(errors.New("boom")), SeverityServer},
+		{"ServiceUnavailable", NewServiceUnavailable("down"), SeverityServer},
+		{"Timeout", fmt.Errorf("wrapping: %w", NewTimeoutError("slow", 0)), SeverityServer},
+		{"Success", &StatusError{ErrStatus: Status{Status: StatusSuccess, Code: http.StatusOK}}, SeverityUnknown},
+		{"Without code", &StatusError{ErrStatus: Status{Status: StatusFailure}}, SeverityUnknown},
+		{"Plain", errors.New("boom"), SeverityUnknown},
+		{"Nil", nil, SeverityUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if severity := SeverityForError(tc.err); severity != tc.expectedSeverity {
+				t.Errorf("expected %s, got %s", tc.expectedSeverity, severity)
+			}
+		})
+	}
+}
+
+func TestIsClientAndServerError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedClient bool
+		expectedServer bool
+	}{
+		{"NotFound", NewNotFound("tests", ""), true, false},
+		{"BadRequest", NewBadRequest("bad"), true, false},
+		{"Conflict", NewConflict("tests", errors.New("exists")), true, false},
+		{"ClientClosedRequest", NewClientClosedRequest("gone"), true, false},
+		{"InternalError", NewInternalError(errors.New("boom")), false, true},
+		{"ServiceUnavailable", NewServiceUnavailable("down"), false, true},
+		{"Wrapped client", fmt.Errorf("wrapping: %w", NewUnauthorized("who")), true, false},
+		{"Wrapped server", fmt.Errorf("wrapping: %w", NewTimeoutError("slow", 0)), false, true},
+		{"Success", &StatusError{ErrStatus: Status{Status: StatusSuccess, Code: http.StatusOK}}, false, false},
+		{"Plain", errors.New("boom"), false, false},
+		{"Nil", nil, false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if isClient := IsClientError(tc.err); isClient != tc.expectedClient {
+				t.Errorf("expected IsClientError %v, got %v", tc.expectedClient, isClient)
+			}
+			if isServer := IsServerError(tc.err); isServer != tc.expectedServer {
+				t.Errorf("expected IsServerError %v, got %v", tc.expectedServer, isServer)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"sync"
+)
+
+// StandardErrorMapper converts an error matching a registered sentinel into a
+// StatusError.
+type StandardErrorMapper func(err error) *StatusError
+
+type standardError struct {
+	sentinel error
+	mapper   StandardErrorMapper
+}
+
+var (
+	standardErrorsLock sync.RWMutex
+	standardErrors     = []standardError{
+		{context.DeadlineExceeded, func(err error) *StatusError { return NewTimeoutError(err.Error(), 0) }},
+		{context.Canceled, func(err error) *StatusError { return NewClientClosedRequest(err.Error()) }},
+		{sql.ErrNoRows, func(err error) *StatusError { return NewNotFound("resource", "") }},
+		{os.ErrNotExist, func(err error) *StatusError { return NewNotFound("resource", "") }},
+	}
+)
+
+// RegisterStandardError registers a mapper that FromStandardError uses for
+// errors matching sentinel (as reported by errors.Is). Mappers registered later
+// take precedence over earlier ones, including the defaults.
+func RegisterStandardError(sentinel error, mapper StandardErrorMapper) {
+	standardErrorsLock.Lock()
+	defer standardErrorsLock.Unlock()
+	standardErrors = append(standardErrors, standardError{sentinel: sentinel, mapper: mapper})
+}
+
+// FromStandardError converts common standard library errors into a StatusError:
+// context.DeadlineExceeded becomes a timeout, context.Canceled a client closed
+// request, and sql.ErrNoRows and os.ErrNotExist become not found. A StatusError
+// is returned as is, and any other error becomes an internal error. Returns nil
+// when err is nil. It supports wrapped errors.
+func FromStandardError(err error) *StatusError {
+	if err == nil {
+		return nil
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr
+	}
+	standardErrorsLock.RLock()
+	defer standardErrorsLock.RUnlock()
+	for i := len(standardErrors) - 1; i >= 0; i-- {
+		if errors.Is(err, standardErrors[i].sentinel) {
+			return standardErrors[i].mapper(err)
+		}
+	}
+	return NewInternalError(err)
+}
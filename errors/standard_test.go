@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestFromStandardError(t *testing.T) {
+	_, notExist := os.Open("/does/not/exist")
+
+	testCases := []struct {
+		name           string
+		err            error
+		expectedReason StatusReason
+		expectedCode   int32
+	}{
+		{"DeadlineExceeded", context.DeadlineExceeded, StatusReasonTimeout, http.StatusGatewayTimeout},
+		{"Canceled", context.Canceled, StatusReasonClientClosedRequest, StatusClientClosedRequest},
+		{"ErrNoRows", sql.ErrNoRows, StatusReasonNotFound, http.StatusNotFound},
+		{"ErrNotExist", notExist, StatusReasonNotFound, http.StatusNotFound},
+		{"Wrapped", fmt.Errorf("query: %w", sql.ErrNoRows), StatusReasonNotFound, http.StatusNotFound},
+		{"StatusError", NewConflict("foo", errors.New("bar")), StatusReasonConflict, http.StatusConflict},
+		{"Fallback", errors.New("boom"), StatusReasonInternalError, http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			statusErr := FromStandardError(tc.err)
+			if statusErr == nil {
+				t.Fatal("expected an error")
+			}
+			if reason := ReasonForError(statusErr); reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+			if statusErr.Code() != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, statusErr.Code())
+			}
+		})
+	}
+
+	if err := FromStandardError(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+func TestRegisterStandardError(t *testing.T) {
+	defer func(saved []standardError) { standardErrors = saved }(standardErrors)
+
+	errLocked := errors.New("locked")
+	RegisterStandardError(errLocked, func(err error) *StatusError { return NewConflict("resource", err) })
+	RegisterStandardError(sql.ErrNoRows, func(err error) *StatusError { return NewBadRequest(err.Error()) })
+
+	if err := FromStandardError(fmt.Errorf("update: %w", errLocked)); !IsConflict(err) {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+	if err := FromStandardError(sql.ErrNoRows); !IsBadRequest(err) {
+		t.Errorf("expected the registered mapper to take precedence, got %v", err)
+	}
+}
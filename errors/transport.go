@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// errorTransport is an http.RoundTripper that converts error responses into
+// StatusErrors.
+type errorTransport struct {
+	base http.RoundTripper
+}
+
+// NewErrorTransport wraps base, or http.DefaultTransport if base is nil, so
+// that 4xx and 5xx responses are returned as the error that FromResponse
+// decodes from them. To satisfy the http.RoundTripper contract, the response
+// itself is closed and not returned along with the error, use DoRequest when
+// the response is needed as well. Successful responses are returned untouched.
+//
+// An http.Client wraps the error in a *url.Error. The classifiers in this
+// package support such wrapped errors.
+func NewErrorTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &errorTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest {
+		return resp, err
+	}
+	_, err = decodeErrorResponse(resp)
+	return nil, err
+}
+
+// DoRequest sends req with client, or http.DefaultClient if client is nil. For
+// 4xx and 5xx responses, both the response and the error that FromResponse
+// decodes from it are returned, and the response body is buffered so that it
+// can still be read by the caller. Bodies longer than MaxResponseBodyBytes are
+// truncated. Successful responses are returned untouched.
+func DoRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest {
+		return resp, err
+	}
+	return decodeErrorResponse(resp)
+}
+
+// decodeErrorResponse reads and closes the body of the error response and
+// returns the response with the body buffered, along with the error that
+// FromResponse decodes from it. Like FromResponse, at most
+// MaxResponseBodyBytes are read, and a longer body is truncated and results in
+// an internal error.
+func decodeErrorResponse(resp *http.Response) (*http.Response, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxResponseBodyBytes+1))
+	resp.Body.Close()
+	if err != nil {
+		return resp, NewInternalError(fmt.Errorf("client error: reading server response: %w", err))
+	}
+	if int64(len(body)) > MaxResponseBodyBytes {
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body[:MaxResponseBodyBytes]))
+		return resp, NewInternalError(fmt.Errorf("client error: server response exceeded the %d byte limit", MaxResponseBodyBytes))
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	decoded := *resp
+	decoded.Body = ioutil.NopCloser(bytes.NewReader(body))
+	err, _ = FromResponse(&decoded)
+	return resp, err
+}
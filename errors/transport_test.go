@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTransportTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			w.Write([]byte("ok"))
+			return
+		}
+		NewNotFound("tests", "1").WriteResponse(w)
+	}))
+}
+
+func TestErrorTransport(t *testing.T) {
+	srv := newTransportTestServer()
+	defer srv.Close()
+
+	transport := NewErrorTransport(nil)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/missing", nil)
+	resp, err := transport.RoundTrip(req)
+	if !IsNotFound(err) {
+		t.Fatalf("expected to be %s, got %v", StatusReasonNotFound, err)
+	}
+	if resp != nil {
+		t.Errorf("expected no response along with the error, got %#v", resp)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/ok", nil)
+	resp, err = transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("expected the body to be untouched, got %q", body)
+	}
+
+	client := &http.Client{Transport: transport}
+	if _, err := client.Get(srv.URL + "/missing"); !IsNotFound(err) {
+		t.Errorf("expected the client error to be %s, got %v", StatusReasonNotFound, err)
+	}
+}
+
+func TestDoRequest(t *testing.T) {
+	srv := newTransportTestServer()
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/missing", nil)
+	resp, err := DoRequest(nil, req)
+	if !IsNotFound(err) {
+		t.Fatalf("expected to be %s, got %v", StatusReasonNotFound, err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected the response to be returned, got %#v", resp)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"reason": "NotFound"`) {
+		t.Errorf("expected the body to be readable, got %q", body)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/ok", nil)
+	resp, err = DoRequest(srv.Client(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("expected the body to be untouched, got %q", body)
+	}
+}
+
+func TestDoRequestBodyLimit(t *testing.T) {
+	defer func(saved int64) { MaxResponseBodyBytes = saved }(MaxResponseBodyBytes)
+	MaxResponseBodyBytes = 16
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(strings.Repeat("x", 1024)))
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := DoRequest(nil, req)
+	if !IsInternalError(err) || !strings.Contains(err.Error(), "exceeded the 16 byte limit") {
+		t.Fatalf("expected the body limit to be exceeded, got %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if len(body) != 16 {
+		t.Errorf("expected the body to be truncated to 16 bytes, got %d", len(body))
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL, nil)
+	if _, err := NewErrorTransport(nil).RoundTrip(req); !IsInternalError(err) || !strings.Contains(err.Error(), "exceeded the 16 byte limit") {
+		t.Errorf("expected the body limit to be exceeded, got %v", err)
+	}
+}
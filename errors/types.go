@@ -1,7 +1,16 @@
 package errors
 
-// Status is a return value for calls that don't return other objects.
+// Status is a return value for calls that don't return other objects. The
+// camelCase JSON keys of a Status and its details are part of the wire format
+// and are checked against testdata/status.golden.json.
 type Status struct {
+	// APIVersion defines the versioned schema of this representation of an
+	// object. It is always StatusAPIVersion for a Status so that generic
+	// clients can identify it.
+	APIVersion string `json:"apiVersion"`
+	// Kind is a string value representing the REST resource this object
+	// represents. It is always StatusKind for a Status.
+	Kind string `json:"kind"`
 	// Status of the operation.
 	// One of: "Success" or "Failure".
 	// +optional
@@ -51,6 +60,26 @@ type StatusDetails struct {
 	RetryAfterSeconds int32 `json:"retryAfterSeconds,omitempty"`
 }
 
+// Sub-reasons of StatusReasonTooManyRequests reported by NewTooManyRequestsWithReason
+const (
+	// TooManyRequestsRateLimited means that a short-term rate limit was exceeded and
+	// the request may be retried soon.
+	TooManyRequestsRateLimited = "RateLimited"
+	// TooManyRequestsQuotaExceeded means that a long-term quota was exhausted and the
+	// request should not be retried until the quota is reset.
+	TooManyRequestsQuotaExceeded = "QuotaExceeded"
+)
+
+// StatusClientClosedRequest is the non-standard HTTP status code used when the
+// client closes the connection before the server has responded.
+const StatusClientClosedRequest = 499
+
+// Values of Status.APIVersion and Status.Kind
+const (
+	StatusAPIVersion = "v1"
+	StatusKind       = "Status"
+)
+
 // Values of Status.Status
 const (
 	StatusSuccess = "Success"
@@ -102,6 +131,12 @@ const (
 	// Status code 409
 	StatusReasonConflict StatusReason = "Conflict"
 
+	// StatusReasonPreconditionFailed means that a precondition of the requested operation,
+	// such as an If-Match header or an expected resource version, was not met. The client
+	// may need to fetch the latest version of the resource before trying again.
+	// Status code 412
+	StatusReasonPreconditionFailed StatusReason = "PreconditionFailed"
+
 	// StatusReasonInvalid means the requested create or update operation cannot be
 	// completed due to invalid data provided as part of the request. The client may
 	// need to alter the request. When set, the client may use the StatusDetails
@@ -134,6 +169,21 @@ const (
 	// Status code 504
 	StatusReasonTimeout StatusReason = "Timeout"
 
+	// StatusReasonRequestTimeout means that the server did not receive a complete request
+	// within the time that it was prepared to wait, for example a slow upload that was aborted.
+	// The client may repeat the request, and should wait at least the number of seconds
+	// specified by the retryAfterSeconds field.
+	// Details (optional):
+	//   "retryAfterSeconds" int32 - the number of seconds before the operation should be retried
+	// Status code 408
+	StatusReasonRequestTimeout StatusReason = "RequestTimeout"
+
+	// StatusReasonClientClosedRequest means that the client canceled the request before the
+	// server finished handling it. The status is rarely seen by the client and mostly serves
+	// to record why the request was abandoned.
+	// Status code 499
+	StatusReasonClientClosedRequest StatusReason = "ClientClosedRequest"
+
 	// StatusReasonTooManyRequests means the server experienced too many requests within a
 	// given window and that the client must wait to perform the action again. A client may
 	// always retry the request that led to this error, although the client should wait at least
@@ -172,6 +222,27 @@ const (
 	// Status code 415
 	StatusReasonUnsupportedMediaType StatusReason = "UnsupportedMediaType"
 
+	// StatusReasonPaymentRequired means that the request cannot be completed until a payment
+	// is made, for example because the account is past due. Retrying the request will not
+	// succeed until the payment issue is resolved.
+	// Status code 402
+	StatusReasonPaymentRequired StatusReason = "PaymentRequired"
+
+	// StatusReasonLocked means that the resource being accessed is locked, for example by
+	// a WebDAV lock held by another client. The request may succeed once the lock is released.
+	// Details (optional):
+	//   "name" string - the name of the locked resource
+	// Status code 423
+	StatusReasonLocked StatusReason = "Locked"
+
+	// StatusReasonFailedDependency means that the action could not be performed on the
+	// resource because it depended on another action that failed, for example another
+	// operation in the same WebDAV batch.
+	// Details (optional):
+	//   "name" string - the name of the resource that could not be modified
+	// Status code 424
+	StatusReasonFailedDependency StatusReason = "FailedDependency"
+
 	// StatusReasonInternalError indicates that an internal error occurred, it is unexpected
 	// and the outcome of the call is unknown.
 	// Details (optional):
@@ -208,6 +279,14 @@ type StatusCause struct {
 	//   "items[0].name" - the field "name" on the first array entry in "items"
 	// +optional
 	Field string `json:"field,omitempty" protobuf:"bytes,3,opt,name=field"`
+	// The value that caused this error, rendered as a string. For example the
+	// rejected value of an invalid field.
+	// +optional
+	Value string `json:"value,omitempty" protobuf:"bytes,4,opt,name=value"`
+	// A machine-readable, application specific code further identifying the
+	// cause of the error.
+	// +optional
+	ErrorCode string `json:"errorCode,omitempty" protobuf:"bytes,5,opt,name=errorCode"`
 }
 
 // CauseType is a machine readable value providing more detail about what
@@ -241,4 +320,20 @@ const (
 	// CauseTypeResourceVersionTooLarge is used to report that the requested resource version
 	// is newer than the data observed by the API server, so the request cannot be served.
 	CauseTypeResourceVersionTooLarge CauseType = "ResourceVersionTooLarge"
+	// CauseTypePermissionRequired is used to report a permission that the caller must
+	// be granted for the request to be allowed.
+	CauseTypePermissionRequired CauseType = "PermissionRequired"
+	// CauseTypeRequestID is used to report the identifier of the request that failed
+	// so that it can be correlated with server-side logs.
+	CauseTypeRequestID CauseType = "RequestID"
+	// CauseTypeDocumentationURL is used to link to documentation describing the error
+	// and how it may be resolved. The URL is reported as the value of the cause.
+	CauseTypeDocumentationURL CauseType = "DocumentationURL"
+	// CauseTypeTooManyRequestsReason is used to report why a request was rejected with
+	// StatusReasonTooManyRequests, e.g. TooManyRequestsRateLimited or
+	// TooManyRequestsQuotaExceeded. The sub-reason is reported as the value of the cause.
+	CauseTypeTooManyRequestsReason CauseType = "TooManyRequestsReason"
+	// CauseTypeRequest is used to report the HTTP method and path of the request that
+	// failed, e.g. "GET /api/v1/users", so that errors can be correlated with routes.
+	CauseTypeRequest CauseType = "Request"
 )
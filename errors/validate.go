@@ -0,0 +1,41 @@
+package errors
+
+import "fmt"
+
+// ValidateStatus checks that a hand-built Status is internally consistent. A
+// successful status must have a 2xx code and no reason, a failed status must
+// have a non-2xx code, and the code must match the one implied by the reason.
+// Internal errors may use any 5xx code. A zero code is allowed since
+// ErrorToAPIStatus fills it in with a default.
+func ValidateStatus(s *Status) error {
+	if s == nil {
+		return fmt.Errorf("status is nil")
+	}
+	success := s.Code >= 200 && s.Code < 300
+	switch s.Status {
+	case StatusSuccess:
+		if s.Code != 0 && !success {
+			return fmt.Errorf("status %q must have a 2xx code, got %d", s.Status, s.Code)
+		}
+		if s.Reason != StatusReasonUnknown {
+			return fmt.Errorf("status %q must not have a reason, got %q", s.Status, s.Reason)
+		}
+		return nil
+	case StatusFailure:
+		if success {
+			return fmt.Errorf("status %q must not have a 2xx code, got %d", s.Status, s.Code)
+		}
+	default:
+		return fmt.Errorf("status must be %q or %q, got %q", StatusSuccess, StatusFailure, s.Status)
+	}
+	if s.Code == 0 || s.Reason == StatusReasonUnknown {
+		return nil
+	}
+	if s.Reason == StatusReasonInternalError && s.Code >= 500 {
+		return nil
+	}
+	if code, ok := lookupReason(s.Reason); ok && int32(code) != s.Code {
+		return fmt.Errorf("reason %q must have code %d, got %d", s.Reason, code, s.Code)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestValidateStatus(t *testing.T) {
+	testCases := []struct {
+		name        string
+		status      *Status
+		expectValid bool
+	}{
+		{name: "Nil", status: nil, expectValid: false},
+		{name: "Success", status: &Status{Status: StatusSuccess, Code: http.StatusOK}, expectValid: true},
+		{name: "Success without code", status: &Status{Status: StatusSuccess}, expectValid: true},
+		{name: "Success with failure code", status: &Status{Status: StatusSuccess, Code: http.StatusNotFound}, expectValid: false},
+		{name: "Success with reason", status: &Status{Status: StatusSuccess, Code: http.StatusOK, Reason: StatusReasonNotFound}, expectValid: false},
+		{name: "Failure with success code", status: &Status{Status: StatusFailure, Code: http.StatusOK}, expectValid: false},
+		{name: "Failure without code", status: &Status{Status: StatusFailure, Reason: StatusReasonNotFound}, expectValid: true},
+		{name: "Failure with unknown reason", status: &Status{Status: StatusFailure, Code: http.StatusTeapot}, expectValid: true},
+		{name: "Mismatched reason and code", status: &Status{Status: StatusFailure, Code: http.StatusBadRequest, Reason: StatusReasonNotFound}, expectValid: false},
+		{name: "Internal error with 5xx code", status: &Status{Status: StatusFailure, Code: http.StatusBadGateway, Reason: StatusReasonInternalError}, expectValid: true},
+		{name: "Unknown status", status: &Status{Status: "Pending", Code: http.StatusOK}, expectValid: false},
+		{name: "NotFound", status: &NewNotFound("tests", "").ErrStatus, expectValid: true},
+		{name: "AlreadyExists", status: &NewAlreadyExists("tests", "").ErrStatus, expectValid: true},
+		{name: "ServerTimeout", status: &NewServerTimeout("tests", 0).ErrStatus, expectValid: true},
+		{name: "InternalError", status: &NewInternalError(errors.New("tests")).ErrStatus, expectValid: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateStatus(tc.status); (err == nil) != tc.expectValid {
+				t.Errorf("expected valid: %t, got error: %v", tc.expectValid, err)
+			}
+		})
+	}
+}
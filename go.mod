@@ -1,9 +1,24 @@
 module github.com/clarkmcc/apiutils
 
-go 1.15
+go 1.19
 
 require (
-	github.com/emicklei/go-restful v2.15.0+incompatible // indirect
 	github.com/stretchr/testify v1.6.1
+	google.golang.org/grpc v1.38.0
 	k8s.io/apimachinery v0.18.4
+	sigs.k8s.io/yaml v1.2.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20191004110552-13f9640d40b9 // indirect
+	golang.org/x/sys v0.0.0-20191022100944-742c48ecaeb7 // indirect
+	golang.org/x/text v0.3.2 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	google.golang.org/protobuf v1.25.0 // indirect
+	gopkg.in/yaml.v2 v2.2.8 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+	k8s.io/klog v1.0.0 // indirect
 )
@@ -0,0 +1,16 @@
+package grpcutil
+
+import (
+	"context"
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor returns an interceptor that converts API status
+// errors returned by handlers into gRPC status errors with ToGRPCError, so that
+// the same error constructors can be shared by HTTP and gRPC handlers.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, ToGRPCError(err)
+	}
+}
@@ -0,0 +1,39 @@
+package grpcutil
+
+import (
+	"context"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/widgets.Widgets/Get"}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.NewNotFound("widget", "")
+	}
+	_, err := interceptor(context.Background(), nil, info, handler)
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.NotFound, s.Code())
+	require.Equal(t, "widget not found", s.Message())
+
+	grpcErr := status.Error(codes.Unavailable, "try later")
+	handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, grpcErr
+	}
+	_, err = interceptor(context.Background(), nil, info, handler)
+	require.Equal(t, grpcErr, err)
+
+	handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	resp, err := interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+}
@@ -0,0 +1,64 @@
+package grpcutil
+
+import (
+	stderrors "errors"
+	"github.com/clarkmcc/apiutils/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reasonCodes maps each known StatusReason to the gRPC code that best
+// describes it.
+var reasonCodes = map[errors.StatusReason]codes.Code{
+	errors.StatusReasonUnknown:               codes.Unknown,
+	errors.StatusReasonUnauthorized:          codes.Unauthenticated,
+	errors.StatusReasonForbidden:             codes.PermissionDenied,
+	errors.StatusReasonNotFound:              codes.NotFound,
+	errors.StatusReasonAlreadyExists:         codes.AlreadyExists,
+	errors.StatusReasonConflict:              codes.Aborted,
+	errors.StatusReasonPreconditionFailed:    codes.FailedPrecondition,
+	errors.StatusReasonInvalid:               codes.InvalidArgument,
+	errors.StatusReasonServerTimeout:         codes.DeadlineExceeded,
+	errors.StatusReasonTimeout:               codes.DeadlineExceeded,
+	errors.StatusReasonRequestTimeout:        codes.DeadlineExceeded,
+	errors.StatusReasonClientClosedRequest:   codes.Canceled,
+	errors.StatusReasonTooManyRequests:       codes.ResourceExhausted,
+	errors.StatusReasonBadRequest:            codes.InvalidArgument,
+	errors.StatusReasonMethodNotAllowed:      codes.Unimplemented,
+	errors.StatusReasonNotAcceptable:         codes.InvalidArgument,
+	errors.StatusReasonRequestEntityTooLarge: codes.ResourceExhausted,
+	errors.StatusReasonUnsupportedMediaType:  codes.InvalidArgument,
+	errors.StatusReasonPaymentRequired:       codes.FailedPrecondition,
+	errors.StatusReasonLocked:                codes.FailedPrecondition,
+	errors.StatusReasonFailedDependency:      codes.FailedPrecondition,
+	errors.StatusReasonInternalError:         codes.Internal,
+	errors.StatusReasonServiceUnavailable:    codes.Unavailable,
+}
+
+// CodeForReason returns the gRPC code for the reason, or codes.Unknown if the
+// reason is not known.
+func CodeForReason(reason errors.StatusReason) codes.Code {
+	if code, ok := reasonCodes[reason]; ok {
+		return code
+	}
+	return codes.Unknown
+}
+
+// ToGRPCError converts an API status error into a gRPC status error with the
+// same message, using CodeForReason for the code. Errors that are already gRPC
+// status errors, and errors that carry no API status, are returned unchanged.
+// It supports wrapped errors.
+func ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	apiStatus := errors.APIStatus(nil)
+	if !stderrors.As(err, &apiStatus) {
+		return err
+	}
+	s := apiStatus.Status()
+	return status.Error(CodeForReason(s.Reason), s.Message)
+}
@@ -0,0 +1,37 @@
+package grpcutil
+
+import (
+	"fmt"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestCodeForReason(t *testing.T) {
+	require.Equal(t, codes.NotFound, CodeForReason(errors.StatusReasonNotFound))
+	require.Equal(t, codes.PermissionDenied, CodeForReason(errors.StatusReasonForbidden))
+	require.Equal(t, codes.Unauthenticated, CodeForReason(errors.StatusReasonUnauthorized))
+	require.Equal(t, codes.InvalidArgument, CodeForReason(errors.StatusReasonInvalid))
+	require.Equal(t, codes.Unknown, CodeForReason(errors.StatusReason("SomethingElse")))
+}
+
+func TestToGRPCError(t *testing.T) {
+	require.NoError(t, ToGRPCError(nil))
+
+	err := ToGRPCError(errors.NewNotFound("widget", ""))
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.NotFound, s.Code())
+	require.Equal(t, "widget not found", s.Message())
+
+	err = ToGRPCError(fmt.Errorf("wrapping: %w", errors.NewAlreadyExists("widget", "")))
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	grpcErr := status.Error(codes.DataLoss, "lost")
+	require.Equal(t, grpcErr, ToGRPCError(grpcErr))
+
+	plain := fmt.Errorf("boom")
+	require.Equal(t, plain, ToGRPCError(plain))
+}
@@ -0,0 +1,45 @@
+package httputils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WriteJSONWithETag writes a non-API object in JSON like WriteRawJSON, with an
+// ETag header computed from the hash of the body. If the status code is 2xx and
+// the request is a GET or HEAD whose If-None-Match header matches the ETag, 304
+// Not Modified is written without a body instead. Other status codes, such as
+// errors, are always written with their body.
+func WriteJSONWithETag(statusCode int, object interface{}, w http.ResponseWriter, r *http.Request) {
+	output, err := json.MarshalIndent(object, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(output))
+	w.Header().Set("ETag", etag)
+	success := statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+	if success && (r.Method == http.MethodGet || r.Method == http.MethodHead) && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(output)
+	flush(w)
+}
+
+// etagMatches returns true if the If-None-Match header matches the ETag. The
+// header may list several ETags, which are compared weakly, or be "*".
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package httputils
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteJSONWithETag(t *testing.T) {
+	object := map[string]string{"name": "test"}
+
+	w := httptest.NewRecorder()
+	WriteJSONWithETag(http.StatusOK, object, w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"name":"test"}`, w.Body.String())
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	testCases := []struct {
+		name         string
+		method       string
+		ifNoneMatch  string
+		expectedCode int
+	}{
+		{"Matching", http.MethodGet, etag, http.StatusNotModified},
+		{"Matching weak", http.MethodGet, "W/" + etag, http.StatusNotModified},
+		{"Matching in list", http.MethodGet, `"other", ` + etag, http.StatusNotModified},
+		{"Wildcard", http.MethodHead, "*", http.StatusNotModified},
+		{"Not matching", http.MethodGet, `"other"`, http.StatusOK},
+		{"Missing", http.MethodGet, "", http.StatusOK},
+		{"Not a GET", http.MethodPut, etag, http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/", nil)
+			if tc.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			WriteJSONWithETag(http.StatusOK, object, w, r)
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, etag, w.Header().Get("ETag"))
+			if tc.expectedCode == http.StatusNotModified {
+				require.Empty(t, w.Body.String())
+			} else {
+				require.JSONEq(t, `{"name":"test"}`, w.Body.String())
+			}
+		})
+	}
+
+	// only successful responses are conditional
+	for _, code := range []int{http.StatusNotFound, http.StatusInternalServerError} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		WriteJSONWithETag(code, object, w, r)
+		require.Equal(t, code, w.Code)
+		require.JSONEq(t, `{"name":"test"}`, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	WriteJSONWithETag(http.StatusOK, map[string]string{"name": "changed"}, w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NotEqual(t, etag, w.Header().Get("ETag"))
+}
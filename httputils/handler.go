@@ -0,0 +1,18 @@
+package httputils
+
+import "net/http"
+
+// Handler adapts fn into an http.HandlerFunc. The object returned by fn is
+// written with WriteRawJSON and a 200 status code, and any error is written
+// with WriteError, so that validation errors such as errors.NewInvalid are
+// returned as a 422 without per-handler boilerplate.
+func Handler(fn func(*http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		obj, err := fn(r)
+		if err != nil {
+			WriteError(err, w)
+			return
+		}
+		WriteRawJSON(http.StatusOK, obj, w)
+	}
+}
@@ -0,0 +1,45 @@
+package httputils
+
+import (
+	"encoding/json"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	type widget struct {
+		Name string `json:"name"`
+	}
+	handler := Handler(func(r *http.Request) (interface{}, error) {
+		var w widget
+		if err := DecodeJSON(r, &w); err != nil {
+			return nil, err
+		}
+		if w.Name == "" {
+			return nil, errors.NewInvalidFromMap("widget", map[string]string{"name": "must not be empty"})
+		}
+		return w, nil
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"test"}`)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"name":"test"}`, w.Body.String())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":""}`)))
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var status errors.Status
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+		require.Equal(t, errors.StatusReasonInvalid, status.Reason)
+		require.Equal(t, "widget is invalid: name: must not be empty", status.Message)
+	})
+}
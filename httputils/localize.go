@@ -0,0 +1,84 @@
+package httputils
+
+import (
+	"github.com/clarkmcc/apiutils/errors"
+	"net/http"
+	"strings"
+)
+
+// WriteErrorLocalized wraps WriteError and translates the message of the error
+// into the locale of the registered errors.LocaleLocalizer that best matches
+// the Accept-Language header of the request, falling back to its default
+// locale. The Reason and Code are never translated. The error is written
+// untranslated if the registered localizer is not an errors.LocaleLocalizer.
+func WriteErrorLocalized(err error, w http.ResponseWriter, r *http.Request) {
+	locales := errors.Locales()
+	if len(locales) == 0 {
+		WriteError(err, w)
+		return
+	}
+	locale := NegotiateLanguage(r.Header.Get("Accept-Language"), locales, locales[0])
+	status := errors.LocalizeStatus(*errors.ErrorToAPIStatus(err), locale)
+	w.Header().Set("Content-Language", locale)
+	WriteError(&errors.StatusError{ErrStatus: status}, w)
+}
+
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+// parseAcceptLanguage parses the language ranges of an Accept-Language header,
+// skipping any that are empty.
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		if len(tag) == 0 {
+			continue
+		}
+		ranges = append(ranges, languageRange{tag: tag, q: parseQuality(params[1:])})
+	}
+	return ranges
+}
+
+// specificity returns how specifically the language range matches the locale,
+// or -1 if it does not match at all. A range matches locales that it is a
+// prefix of, and locales that are a prefix of it, so that "en" and "en-US"
+// match each other.
+func (l languageRange) specificity(locale string) int {
+	switch {
+	case l.tag == locale:
+		return 2
+	case strings.HasPrefix(locale, l.tag+"-") || strings.HasPrefix(l.tag, locale+"-"):
+		return 1
+	case l.tag == "*":
+		return 0
+	}
+	return -1
+}
+
+// NegotiateLanguage returns the available locale with the highest quality in
+// the Accept-Language header, or defaultLocale if none of them are acceptable.
+// Ties are broken by the order of the language ranges in the header, then by
+// preferring exact matches, and then by the order of the available locales.
+func NegotiateLanguage(header string, available []string, defaultLocale string) string {
+	ranges := parseAcceptLanguage(header)
+	best, bestQ, bestPosition, bestSpecificity := defaultLocale, 0.0, len(ranges), -1
+	for _, locale := range available {
+		q, position, specificity := 0.0, -1, -1
+		for i, r := range ranges {
+			if s := r.specificity(strings.ToLower(locale)); s > specificity {
+				q, position, specificity = r.q, i, s
+			}
+		}
+		if specificity < 0 || q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && (position < bestPosition || (position == bestPosition && specificity > bestSpecificity))) {
+			best, bestQ, bestPosition, bestSpecificity = locale, q, position, specificity
+		}
+	}
+	return best
+}
@@ -0,0 +1,78 @@
+package httputils
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testLocalizer struct{}
+
+func (testLocalizer) Localize(reason errors.StatusReason, format string, args ...interface{}) string {
+	return fmt.Sprintf(format, args...)
+}
+
+func (testLocalizer) Locales() []string {
+	return []string{"en", "de", "fr-CA"}
+}
+
+func (testLocalizer) LocalizeStatus(locale string, status errors.Status) string {
+	switch locale {
+	case "de":
+		return fmt.Sprintf("%s nicht gefunden", status.Details.Name)
+	case "fr-CA":
+		return fmt.Sprintf("%s introuvable", status.Details.Name)
+	}
+	return status.Message
+}
+
+func TestWriteErrorLocalized(t *testing.T) {
+	errors.SetLocalizer(testLocalizer{})
+	defer errors.SetLocalizer(nil)
+
+	testCases := []struct {
+		acceptLanguage  string
+		expectedLocale  string
+		expectedMessage string
+	}{
+		{"de", "de", "widget nicht gefunden"},
+		{"de-AT, en;q=0.5", "de", "widget nicht gefunden"},
+		{"fr, de;q=0.8", "fr-CA", "widget introuvable"},
+		{"en;q=0.2, de;q=0.9", "de", "widget nicht gefunden"},
+		{"ja", "en", "widget not found"},
+		{"", "en", "widget not found"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.acceptLanguage, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tc.acceptLanguage)
+			w := httptest.NewRecorder()
+			WriteErrorLocalized(errors.NewNotFound("widget", ""), w, r)
+
+			require.Equal(t, http.StatusNotFound, w.Code)
+			require.Equal(t, tc.expectedLocale, w.Header().Get("Content-Language"))
+			var status errors.Status
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+			require.Equal(t, tc.expectedMessage, status.Message)
+			require.Equal(t, errors.StatusReasonNotFound, status.Reason)
+		})
+	}
+}
+
+func TestWriteErrorLocalizedWithoutLocales(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "de")
+	w := httptest.NewRecorder()
+	WriteErrorLocalized(errors.NewNotFound("widget", ""), w, r)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Empty(t, w.Header().Get("Content-Language"))
+	var status errors.Status
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, "widget not found", status.Message)
+}
@@ -0,0 +1,62 @@
+package httputils
+
+import (
+	"github.com/clarkmcc/apiutils/errors"
+	"net/http"
+)
+
+// ItemResult is the outcome of processing a single item of a batch request.
+type ItemResult struct {
+	// ID identifies the item within the batch.
+	ID string
+	// Err is the error that processing the item failed with, or nil if it
+	// succeeded.
+	Err error
+}
+
+// MultiStatus is the body of a 207 Multi-Status response, reporting the
+// outcome of each item of a batch request.
+type MultiStatus struct {
+	// Code is always http.StatusMultiStatus.
+	Code int32 `json:"code"`
+	// Items holds the outcome of each item, in the order of the request.
+	Items []ItemStatus `json:"items"`
+}
+
+// ItemStatus is the outcome of a single item in a MultiStatus.
+type ItemStatus struct {
+	// ID identifies the item within the batch.
+	ID string `json:"id"`
+	// Status is a Success status for items that succeeded, or the status of
+	// the error for items that failed.
+	Status errors.Status `json:"status"`
+}
+
+// NewMultiStatus converts the results of a batch request into a MultiStatus.
+// The error of each failed item is reported to the ErrorObserver and redacted
+// if RedactInternalErrors is set, in the same way as by WriteError.
+func NewMultiStatus(results []ItemResult) MultiStatus {
+	out := MultiStatus{
+		Code:  http.StatusMultiStatus,
+		Items: make([]ItemStatus, 0, len(results)),
+	}
+	for _, r := range results {
+		status := errors.Status{
+			APIVersion: errors.StatusAPIVersion,
+			Kind:       errors.StatusKind,
+			Status:     errors.StatusSuccess,
+			Code:       http.StatusOK,
+		}
+		if r.Err != nil {
+			status = *observedStatus(r.Err)
+		}
+		out.Items = append(out.Items, ItemStatus{ID: r.ID, Status: status})
+	}
+	return out
+}
+
+// WriteMultiStatus writes the results of a batch request as a 207 Multi-Status
+// response, so that clients can process mixed successes and failures.
+func WriteMultiStatus(results []ItemResult, w http.ResponseWriter) {
+	WriteRawJSON(http.StatusMultiStatus, NewMultiStatus(results), w)
+}
@@ -0,0 +1,71 @@
+package httputils
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteMultiStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteMultiStatus([]ItemResult{
+		{ID: "a"},
+		{ID: "b", Err: errors.NewForbidden("widget", fmt.Errorf("not allowed"))},
+		{ID: "c"},
+	}, w)
+	require.Equal(t, http.StatusMultiStatus, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var out MultiStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+	require.Equal(t, int32(http.StatusMultiStatus), out.Code)
+	require.Len(t, out.Items, 3)
+
+	require.Equal(t, "a", out.Items[0].ID)
+	require.Equal(t, errors.StatusSuccess, out.Items[0].Status.Status)
+	require.Equal(t, int32(http.StatusOK), out.Items[0].Status.Code)
+
+	require.Equal(t, "b", out.Items[1].ID)
+	require.Equal(t, errors.StatusFailure, out.Items[1].Status.Status)
+	require.Equal(t, errors.StatusReasonForbidden, out.Items[1].Status.Reason)
+	require.Equal(t, int32(http.StatusForbidden), out.Items[1].Status.Code)
+
+	require.Equal(t, "c", out.Items[2].ID)
+	require.Equal(t, errors.StatusSuccess, out.Items[2].Status.Status)
+}
+
+func TestNewMultiStatusEmpty(t *testing.T) {
+	out, err := json.Marshal(NewMultiStatus(nil))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"code":207,"items":[]}`, string(out))
+}
+
+func TestNewMultiStatusRedactsInternalErrors(t *testing.T) {
+	defer func(saved bool) { RedactInternalErrors = saved }(RedactInternalErrors)
+	defer func(saved func(*errors.Status)) { ErrorObserver = saved }(ErrorObserver)
+	var observed []*errors.Status
+	ErrorObserver = func(status *errors.Status) {
+		observed = append(observed, status)
+	}
+	RedactInternalErrors = true
+
+	out := NewMultiStatus([]ItemResult{
+		{ID: "a"},
+		{ID: "b", Err: errors.NewInternalError(fmt.Errorf("db password=hunter2"))},
+		{ID: "c", Err: errors.NewNotFound("widget", "c")},
+	})
+	body, err := json.Marshal(out)
+	require.NoError(t, err)
+	require.NotContains(t, string(body), "hunter2")
+	require.Equal(t, "Internal error occurred", out.Items[1].Status.Message)
+	require.Nil(t, out.Items[1].Status.Details)
+	require.Equal(t, errors.StatusReasonNotFound, out.Items[2].Status.Reason)
+
+	require.Len(t, observed, 2)
+	require.Contains(t, observed[0].Message, "hunter2")
+	require.Equal(t, errors.StatusReasonNotFound, observed[1].Reason)
+}
@@ -0,0 +1,149 @@
+package httputils
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Serializer encodes an object into the bytes written for a particular media
+// type.
+type Serializer func(object interface{}) ([]byte, error)
+
+// Negotiator writes objects using the serializer that best matches the Accept
+// header of the request. Serializers are registered by media type, and the
+// default media type is used when the request does not accept any of them.
+type Negotiator struct {
+	defaultType string
+	mediaTypes  []string
+	serializers map[string]Serializer
+}
+
+// NewNegotiator returns a Negotiator that serializes application/json by
+// default.
+func NewNegotiator() *Negotiator {
+	n := &Negotiator{
+		defaultType: "application/json",
+		serializers: map[string]Serializer{},
+	}
+	n.Register("application/json", func(object interface{}) ([]byte, error) {
+		return json.MarshalIndent(object, "", "  ")
+	})
+	return n
+}
+
+// Register registers the serializer for the media type, replacing any
+// serializer that was previously registered for it. It is not safe to call
+// Register concurrently with WriteNegotiated.
+func (n *Negotiator) Register(mediaType string, serializer Serializer) {
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := n.serializers[mediaType]; !ok {
+		n.mediaTypes = append(n.mediaTypes, mediaType)
+	}
+	n.serializers[mediaType] = serializer
+}
+
+// WriteNegotiated writes the object using the serializer selected by the
+// Accept header of the request. If serialization fails, a 500 is written the
+// same way WriteRawJSON does.
+func (n *Negotiator) WriteNegotiated(statusCode int, object interface{}, w http.ResponseWriter, r *http.Request) {
+	mediaType := NegotiateContentType(r.Header.Get("Accept"), n.mediaTypes, n.defaultType)
+	output, err := n.serializers[mediaType](object)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(statusCode)
+	w.Write(output)
+}
+
+// DefaultNegotiator is the Negotiator used by WriteNegotiated.
+var DefaultNegotiator = NewNegotiator()
+
+// WriteNegotiated writes the object using the DefaultNegotiator.
+func WriteNegotiated(statusCode int, object interface{}, w http.ResponseWriter, r *http.Request) {
+	DefaultNegotiator.WriteNegotiated(statusCode, object, w, r)
+}
+
+// mediaRange is a single media range of an Accept header.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses the media ranges of an Accept header, skipping any that
+// are malformed.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if strings.Count(mediaType, "/") != 1 {
+			continue
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: parseQuality(params[1:])})
+	}
+	return ranges
+}
+
+// parseQuality returns the value of the q parameter among the parameters of a
+// media or language range, or 1 if it is missing or malformed.
+func parseQuality(params []string) float64 {
+	q := 1.0
+	for _, param := range params {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "q" {
+			continue
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil && v >= 0 && v <= 1 {
+			q = v
+		}
+	}
+	return q
+}
+
+// specificity returns how specifically the media range matches the media type,
+// or -1 if it does not match at all.
+func (m mediaRange) specificity(mediaType string) int {
+	switch {
+	case m.mediaType == mediaType:
+		return 2
+	case m.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(m.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(m.mediaType, "*")):
+		return 1
+	}
+	return -1
+}
+
+// NegotiateContentType returns the available media type with the highest
+// quality in the Accept header, or defaultType if none of them are acceptable.
+// Media ranges may use the */* and type/* wildcards, in which case the most
+// specific range matching an available type determines its quality. Ties are
+// broken by the order of the media ranges in the header, and then by the order
+// of the available types.
+func NegotiateContentType(header string, available []string, defaultType string) string {
+	ranges := parseAccept(header)
+	if len(ranges) == 0 {
+		return defaultType
+	}
+	best, bestQ, bestPosition := defaultType, 0.0, len(ranges)
+	for _, mediaType := range available {
+		mediaType = strings.ToLower(mediaType)
+		q, position, specificity := 0.0, -1, -1
+		for i, r := range ranges {
+			if s := r.specificity(mediaType); s > specificity {
+				q, position, specificity = r.q, i, s
+			}
+		}
+		if specificity < 0 || q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && position < bestPosition) {
+			best, bestQ, bestPosition = mediaType, q, position
+		}
+	}
+	return best
+}
@@ -0,0 +1,89 @@
+package httputils
+
+import (
+	"encoding/json"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const protobufMediaType = "application/vnd.api.protobuf"
+
+func TestNegotiator_WriteNegotiated(t *testing.T) {
+	n := NewNegotiator()
+	n.Register(protobufMediaType, func(object interface{}) ([]byte, error) {
+		return []byte("protobuf"), nil
+	})
+	status := errors.NewNotFound("test", "").Status()
+
+	var testCases = []struct {
+		name                string
+		accept              string
+		expectedContentType string
+	}{
+		{"JSON default", "", "application/json"},
+		{"Explicit JSON", "application/json", "application/json"},
+		{"Unacceptable falls back to JSON", "text/html", "application/json"},
+		{"Protobuf", protobufMediaType, protobufMediaType},
+		{"Protobuf weighted", "application/json;q=0.5, " + protobufMediaType + ";q=0.9", protobufMediaType},
+		{"JSON weighted", "application/json;q=0.9, " + protobufMediaType + ";q=0.5", "application/json"},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if len(c.accept) > 0 {
+				r.Header.Set("Accept", c.accept)
+			}
+			w := httptest.NewRecorder()
+			n.WriteNegotiated(http.StatusNotFound, status, w, r)
+
+			require.Equal(t, http.StatusNotFound, w.Code)
+			require.Equal(t, c.expectedContentType, w.Header().Get("Content-Type"))
+			if c.expectedContentType == protobufMediaType {
+				require.Equal(t, "protobuf", w.Body.String())
+			} else {
+				var decoded errors.Status
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+				require.Equal(t, status, decoded)
+			}
+		})
+	}
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	available := []string{"application/json", "application/yaml", "text/plain"}
+
+	var testCases = []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"Empty header", "", "default"},
+		{"Exact match", "application/yaml", "application/yaml"},
+		{"Case insensitive", "Application/YAML", "application/yaml"},
+		{"Quality values", "application/json;q=0.8, application/yaml;q=0.9", "application/yaml"},
+		{"Whitespace around params", "application/json ; q=0.8 , application/yaml ; q = 0.9", "application/yaml"},
+		{"Other params ignored", "application/json;charset=utf-8;q=0.5, text/plain;q=0.4", "application/json"},
+		{"Tie broken by header order", "application/yaml, application/json", "application/yaml"},
+		{"Tie broken by header order reversed", "application/json, application/yaml", "application/json"},
+		{"Any wildcard", "*/*", "application/json"},
+		{"Subtype wildcard", "text/*", "text/plain"},
+		{"Specific range overrides wildcard", "application/*;q=0.9, application/json;q=0.1", "application/yaml"},
+		{"Wildcard lower than exact", "*/*;q=0.1, text/plain", "text/plain"},
+		{"Zero quality excludes", "application/json;q=0, */*;q=0.5", "application/yaml"},
+		{"All excluded", "application/json;q=0, application/yaml;q=0, text/plain;q=0", "default"},
+		{"Unavailable", "text/html", "default"},
+		{"Malformed range skipped", "garbage, application/yaml", "application/yaml"},
+		{"Invalid quality treated as 1", "application/json;q=abc, application/yaml;q=0.9", "application/json"},
+		{"Out of range quality treated as 1", "application/json;q=2, application/yaml;q=0.9", "application/json"},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, NegotiateContentType(c.header, available, "default"))
+		})
+	}
+}
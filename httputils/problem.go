@@ -0,0 +1,40 @@
+package httputils
+
+import (
+	"github.com/clarkmcc/apiutils/errors"
+	"net/http"
+)
+
+// Problem is an RFC 7807 Problem Details document.
+type Problem struct {
+	// Type is a URI identifying the problem type. It is the documentation URL
+	// of the error if it has one, or "about:blank".
+	Type string `json:"type"`
+	// Title is a short summary of the problem type, the text of the status code.
+	Title string `json:"title"`
+	// Status is the HTTP status code.
+	Status int `json:"status"`
+	// Detail is the human-readable message of the error.
+	Detail string `json:"detail,omitempty"`
+	// Reason is the machine-readable reason of the error, as an extension member.
+	Reason errors.StatusReason `json:"reason,omitempty"`
+}
+
+// WriteProblemJSON writes the appropriate error to the response writer as an
+// RFC 7807 Problem Details document with the application/problem+json
+// Content-Type, for clients that do not understand the Status written by
+// WriteError.
+func WriteProblemJSON(err error, w http.ResponseWriter) {
+	status := errorStatus(err, w)
+	problemType := "about:blank"
+	if url, ok := errors.DocumentationURLForError(&errors.StatusError{ErrStatus: *status}); ok {
+		problemType = url
+	}
+	writeJSON(int(status.Code), "application/problem+json", Problem{
+		Type:   problemType,
+		Title:  http.StatusText(int(status.Code)),
+		Status: int(status.Code),
+		Detail: status.Message,
+		Reason: status.Reason,
+	}, w)
+}
@@ -0,0 +1,33 @@
+package httputils
+
+import (
+	"encoding/json"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteProblemJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteProblemJSON(errors.NewNotFound("widget", ""), w)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Not Found",
+		"status": 404,
+		"detail": "widget not found",
+		"reason": "NotFound"
+	}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	WriteProblemJSON(errors.NewTooManyRequests("slow down", 5).WithDocumentationURL("https://example.com/rate-limits"), w)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "5", w.Header().Get("Retry-After"))
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	require.Equal(t, "https://example.com/rate-limits", problem.Type)
+	require.Equal(t, errors.StatusReasonTooManyRequests, problem.Reason)
+}
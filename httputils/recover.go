@@ -0,0 +1,33 @@
+package httputils
+
+import (
+	"fmt"
+	"github.com/clarkmcc/apiutils/errors"
+	"log"
+	"net/http"
+)
+
+// Recover is a middleware that recovers from panics in the next handler and
+// writes them to the client as an internal error. Panics with
+// http.ErrAbortHandler are re-panicked so that the server can abort the
+// response as usual.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+			log.Printf("httputils: panic serving %s %s: %v", r.Method, r.URL.Path, err)
+			WriteError(errors.NewInternalError(err), w)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,63 @@
+package httputils
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"github.com/clarkmcc/apiutils/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// MaxRequestBodyBytes is the maximum number of bytes that DecodeJSON will read
+// from a request body.
+var MaxRequestBodyBytes int64 = 4 << 20
+
+// DecodeJSON decodes the JSON request body into v. It returns a bad request
+// error if the body is empty or malformed, and a request entity too large error
+// if the body exceeds MaxRequestBodyBytes or the limit of LimitRequestBody, so
+// that the error can be passed straight to WriteError.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	if r.Body == nil {
+		return errors.NewBadRequest("request body is required")
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxRequestBodyBytes+1))
+	if maxBytesErr := (*http.MaxBytesError)(nil); stderrors.As(err, &maxBytesErr) {
+		return errors.NewRequestEntityTooLargeError(fmt.Sprintf("limit is %d bytes", maxBytesErr.Limit))
+	}
+	if err != nil {
+		return errors.NewBadRequest(fmt.Sprintf("reading request body: %v", err))
+	}
+	if int64(len(body)) > MaxRequestBodyBytes {
+		return errors.NewRequestEntityTooLargeError(fmt.Sprintf("limit is %d bytes", MaxRequestBodyBytes))
+	}
+	if len(body) == 0 {
+		return errors.NewBadRequest("request body is required")
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.NewBadRequest(fmt.Sprintf("malformed request body: %v", err))
+	}
+	return nil
+}
+
+// LimitRequestBody is a middleware that rejects requests with a body larger
+// than maxBytes with a request entity too large error. Requests that declare a
+// larger Content-Length are rejected before reaching the next handler, and the
+// bodies of all other requests are wrapped with http.MaxBytesReader, so that
+// reading past the limit fails with an *http.MaxBytesError. DecodeJSON converts
+// that error into a request entity too large error.
+func LimitRequestBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				WriteError(errors.NewRequestEntityTooLargeError(fmt.Sprintf("limit is %d bytes", maxBytes)), w)
+				return
+			}
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package httputils
+
+import (
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		var p payload
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"test"}`))
+		require.NoError(t, DecodeJSON(r, &p))
+		require.Equal(t, "test", p.Name)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		var p payload
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":`))
+		err := DecodeJSON(r, &p)
+		require.True(t, errors.IsBadRequest(err))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		var p payload
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		err := DecodeJSON(r, &p)
+		require.True(t, errors.IsBadRequest(err))
+		require.Equal(t, "request body is required", err.Error())
+	})
+
+	t.Run("Oversized", func(t *testing.T) {
+		defer func(limit int64) { MaxRequestBodyBytes = limit }(MaxRequestBodyBytes)
+		MaxRequestBodyBytes = 8
+
+		var p payload
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"test"}`))
+		err := DecodeJSON(r, &p)
+		require.True(t, errors.IsRequestEntityTooLargeError(err))
+	})
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	handler := LimitRequestBody(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p map[string]string
+		if err := DecodeJSON(r, &p); err != nil {
+			WriteError(err, w)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Run("Under", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"b"}`)))
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Over", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"0123456789abcdef"}`)))
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Contains(t, w.Body.String(), "limit is 16 bytes")
+	})
+
+	t.Run("Over without Content-Length", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"0123456789abcdef"}`))
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Contains(t, w.Body.String(), "limit is 16 bytes")
+	})
+}
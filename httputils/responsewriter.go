@@ -2,30 +2,254 @@ package httputils
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/clarkmcc/apiutils/errors"
 	"net/http"
+	"sigs.k8s.io/yaml"
 	"strconv"
+	"strings"
 )
 
-// WriteRawJSON writes a non-API object in JSON.
+// WriteRawJSON writes a non-API object in JSON. The response is flushed if the
+// writer is an http.Flusher.
 func WriteRawJSON(statusCode int, object interface{}, w http.ResponseWriter) {
+	writeJSON(statusCode, "application/json", object, w)
+}
+
+// WriteJSON writes the value in JSON the same way as WriteRawJSON, with the type
+// of the value stated explicitly, e.g. WriteJSON[User](http.StatusOK, user, w).
+func WriteJSON[T any](statusCode int, value T, w http.ResponseWriter) {
+	writeJSON(statusCode, "application/json", value, w)
+}
+
+// writeJSON writes the object in JSON with the provided Content-Type.
+func writeJSON(statusCode int, contentType string, object interface{}, w http.ResponseWriter) {
 	output, err := json.MarshalIndent(object, "", "  ")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	w.Write(output)
+	flush(w)
+}
+
+// WriteRawYAML writes a non-API object in YAML. The response is flushed if the
+// writer is an http.Flusher.
+func WriteRawYAML(statusCode int, object interface{}, w http.ResponseWriter) {
+	output, err := yaml.Marshal(object)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
 	w.WriteHeader(statusCode)
 	w.Write(output)
+	flush(w)
 }
 
+// flush flushes the response writer if it supports it, so that responses
+// written on streaming endpoints are not buffered indefinitely.
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ErrorObserver, when set, is called by WriteError, WriteProblemJSON and
+// NewMultiStatus with the final status of every error before it is written,
+// e.g. to count errors by reason. It must be set before any handlers run, since
+// it is read without synchronization, and it must be safe for concurrent use
+// since it is called from every handler.
+var ErrorObserver func(*errors.Status)
+
+// RedactInternalErrors, when set, makes WriteError, WriteProblemJSON and
+// NewMultiStatus replace the message and causes of internal and unknown 5xx
+// errors with a generic message, so that details of the failure such as
+// secrets are not leaked to clients. The ErrorObserver still receives the
+// original status. It must be set before any handlers run.
+var RedactInternalErrors = false
+
+// redactedMessage is the message written for redacted internal errors.
+const redactedMessage = "Internal error occurred"
+
 // WriteError wraps WriteRawJSON and writes the appropriate error to the response writer
 func WriteError(err error, w http.ResponseWriter) {
-	status := errors.ErrorToAPIStatus(err)
+	status := errorStatus(err, w)
+	WriteRawJSON(int(status.Code), status, w)
+}
+
+// errorStatus returns the status to write for err, see observedStatus. It also
+// sets the Retry-After header when the status indicates a retry after period.
+func errorStatus(err error, w http.ResponseWriter) *errors.Status {
+	status := observedStatus(err)
 	// when writing an error, check to see if the status indicates a retry after period
 	if status.Details != nil && status.Details.RetryAfterSeconds > 0 {
 		delay := strconv.Itoa(int(status.Details.RetryAfterSeconds))
 		w.Header().Set("Retry-After", delay)
 	}
-	WriteRawJSON(int(status.Code), status, w)
+	return status
+}
+
+// observedStatus returns the status to write for err, after reporting it to
+// the ErrorObserver and redacting it if RedactInternalErrors is set.
+func observedStatus(err error) *errors.Status {
+	status := errors.ErrorToAPIStatus(err)
+	if ErrorObserver != nil {
+		ErrorObserver(status)
+	}
+	if RedactInternalErrors {
+		status = redact(status)
+	}
+	return status
+}
+
+// traceableCauses are the cause types that are kept in redacted statuses, since
+// they identify the failed request rather than describe the failure and are
+// what clients need to report an opaque error.
+var traceableCauses = map[errors.CauseType]bool{
+	errors.CauseTypeRequestID:        true,
+	errors.CauseTypeRequest:          true,
+	errors.CauseTypeDocumentationURL: true,
+}
+
+// redact returns a copy of the status without the message and causes of
+// internal and unknown 5xx errors, except for the traceableCauses. Other
+// statuses are returned unchanged.
+func redact(status *errors.Status) *errors.Status {
+	if status.Code < http.StatusInternalServerError ||
+		(status.Reason != errors.StatusReasonInternalError && status.Reason != errors.StatusReasonUnknown) {
+		return status
+	}
+	out := *status
+	out.Message = redactedMessage
+	out.Details = nil
+	if status.Details == nil {
+		return &out
+	}
+	var causes []errors.StatusCause
+	for _, cause := range status.Details.Causes {
+		if traceableCauses[cause.Type] {
+			causes = append(causes, cause)
+		}
+	}
+	if len(causes) > 0 || status.Details.RetryAfterSeconds > 0 {
+		out.Details = &errors.StatusDetails{
+			RetryAfterSeconds: status.Details.RetryAfterSeconds,
+			Causes:            causes,
+		}
+	}
+	return &out
+}
+
+// WriteErrorWithLogger wraps WriteError and calls log with the error when it
+// results in a 5xx response. Client errors are not logged.
+func WriteErrorWithLogger(err error, w http.ResponseWriter, log func(error)) {
+	if status := errors.ErrorToAPIStatus(err); status.Code >= http.StatusInternalServerError {
+		log(err)
+	}
+	WriteError(err, w)
+}
+
+// WriteErrorAndReturn wraps WriteError and returns err unchanged, so that the
+// handler can log the full error chain that was reduced to a status for the
+// client.
+func WriteErrorAndReturn(err error, w http.ResponseWriter) error {
+	WriteError(err, w)
+	return err
+}
+
+// WriteErrorWithWarnings wraps WriteError and adds one Warning header with the
+// miscellaneous persistent warning code 299 for each warning, e.g. to flag the
+// use of deprecated fields alongside the error.
+func WriteErrorWithWarnings(err error, warnings []string, w http.ResponseWriter) {
+	for _, warning := range warnings {
+		w.Header().Add("Warning", "299 - "+quoteString(warning))
+	}
+	WriteError(err, w)
+}
+
+// quoteString returns s as an HTTP quoted-string as defined by RFC 7230
+// section 3.2.6. Unlike strconv.Quote, only '"' and '\' are escaped, since
+// HTTP does not support escape sequences such as \u00e9, and control
+// characters, which are not allowed in header values, are replaced with spaces.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteRune(r)
+		case r < ' ' || r == 0x7f:
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// SecureHeaders are the headers set by SecureWriteRawJSON and SecureWriteError
+// for defense-in-depth.
+var SecureHeaders = map[string]string{
+	"X-Content-Type-Options": "nosniff",
+}
+
+// SecureWriteRawJSON wraps WriteRawJSON and also sets the SecureHeaders.
+func SecureWriteRawJSON(statusCode int, object interface{}, w http.ResponseWriter) {
+	setSecureHeaders(w)
+	WriteRawJSON(statusCode, object, w)
+}
+
+// SecureWriteError wraps WriteError and also sets the SecureHeaders.
+func SecureWriteError(err error, w http.ResponseWriter) {
+	setSecureHeaders(w)
+	WriteError(err, w)
+}
+
+// setSecureHeaders sets the SecureHeaders on the response writer.
+func setSecureHeaders(w http.ResponseWriter) {
+	for k, v := range SecureHeaders {
+		w.Header().Set(k, v)
+	}
+}
+
+// WriteErrorWithRequestID wraps WriteError and includes the request ID in the
+// written status as a RequestID cause and in the X-Request-Id header, so that
+// clients can reference the failed request when reporting problems.
+func WriteErrorWithRequestID(err error, w http.ResponseWriter, requestID string) {
+	w.Header().Set("X-Request-Id", requestID)
+	WriteError(withCause(err, errors.StatusCause{
+		Type:    errors.CauseTypeRequestID,
+		Message: fmt.Sprintf("request ID: %s", requestID),
+		Value:   requestID,
+	}), w)
+}
+
+// WriteErrorForRequest wraps WriteError and includes the method and path of the
+// request in the written status as a Request cause, e.g. "GET /api/v1/users",
+// so that errors reported by clients can be correlated with routes.
+func WriteErrorForRequest(err error, w http.ResponseWriter, r *http.Request) {
+	request := r.Method + " " + r.URL.Path
+	WriteError(withCause(err, errors.StatusCause{
+		Type:    errors.CauseTypeRequest,
+		Message: fmt.Sprintf("request: %s", request),
+		Value:   request,
+	}), w)
+}
+
+// withCause returns a copy of the API status of err with the cause appended to
+// its details. The original error is not modified.
+func withCause(err error, cause errors.StatusCause) error {
+	out := (&errors.StatusError{ErrStatus: *errors.ErrorToAPIStatus(err)}).DeepCopy()
+	if out.ErrStatus.Details == nil {
+		out.ErrStatus.Details = &errors.StatusDetails{}
+	}
+	out.ErrStatus.Details.Causes = append(out.ErrStatus.Details.Causes, cause)
+	return out
 }
@@ -1,10 +1,14 @@
 package httputils
 
 import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"github.com/clarkmcc/apiutils/errors"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
+	"sigs.k8s.io/yaml"
 	"testing"
 )
 
@@ -21,3 +25,233 @@ func TestWriteError(t *testing.T) {
 	require.True(t, hasError)
 	require.True(t, errors.IsNotFound(err))
 }
+
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes++
+	r.ResponseRecorder.Flush()
+}
+
+func TestWriteErrorFlush(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w.Write([]byte("data: first event\n\n"))
+	WriteError(errors.NewInternalError(fmt.Errorf("stream failed")), w)
+	require.Equal(t, 1, w.flushes)
+	require.Contains(t, w.Body.String(), "stream failed")
+
+	WriteRawYAML(http.StatusOK, map[string]string{"a": "b"}, w)
+	require.Equal(t, 2, w.flushes)
+}
+
+func TestWriteRawYAML(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteRawYAML(http.StatusNotFound, errors.ErrorToAPIStatus(errors.NewNotFound("test", "")), w)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+
+	var status errors.Status
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, errors.StatusReasonNotFound, status.Reason)
+	require.Equal(t, int32(http.StatusNotFound), status.Code)
+	require.Equal(t, "test not found", status.Message)
+
+	w = httptest.NewRecorder()
+	WriteRawYAML(http.StatusOK, func() {}, w)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestWriteJSON(t *testing.T) {
+	type user struct {
+		Name  string `json:"name"`
+		Admin bool   `json:"admin"`
+	}
+
+	w := httptest.NewRecorder()
+	WriteJSON[user](http.StatusCreated, user{Name: "foo", Admin: true}, w)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var decoded user
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+	require.Equal(t, user{Name: "foo", Admin: true}, decoded)
+}
+
+func TestWriteErrorWithLogger(t *testing.T) {
+	var logged []error
+	log := func(err error) {
+		logged = append(logged, err)
+	}
+
+	internal := errors.NewInternalError(fmt.Errorf("boom"))
+	w := httptest.NewRecorder()
+	WriteErrorWithLogger(internal, w, log)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, []error{internal}, logged)
+
+	w = httptest.NewRecorder()
+	WriteErrorWithLogger(errors.NewNotFound("test", ""), w, log)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Len(t, logged, 1)
+}
+
+func TestWriteErrorAndReturn(t *testing.T) {
+	notFound := errors.NewNotFound("widget", "")
+	w := httptest.NewRecorder()
+	require.Equal(t, notFound, WriteErrorAndReturn(notFound, w))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	cause := fmt.Errorf("connection refused")
+	original := fmt.Errorf("loading widget: %w", cause)
+	w = httptest.NewRecorder()
+	err := WriteErrorAndReturn(original, w)
+	require.Equal(t, original, err)
+	require.True(t, stderrors.Is(err, cause))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestWriteErrorWithWarnings(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteErrorWithWarnings(errors.NewBadRequest("invalid"), []string{
+		"spec.size is deprecated",
+		`use "spec.replicas" instead`,
+	}, w)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, []string{
+		`299 - "spec.size is deprecated"`,
+		`299 - "use \"spec.replicas\" instead"`,
+	}, w.Header()["Warning"])
+
+	// only quotes and backslashes are escaped, and control characters are
+	// replaced
+	w = httptest.NewRecorder()
+	WriteErrorWithWarnings(errors.NewBadRequest("invalid"), []string{"café\\path\nnext\x00"}, w)
+	require.Equal(t, []string{`299 - "café\\path next "`}, w.Header()["Warning"])
+
+	w = httptest.NewRecorder()
+	WriteErrorWithWarnings(errors.NewBadRequest("invalid"), nil, w)
+	require.Empty(t, w.Header()["Warning"])
+}
+
+func TestRedactInternalErrors(t *testing.T) {
+	defer func(saved bool) { RedactInternalErrors = saved }(RedactInternalErrors)
+	defer func(saved func(*errors.Status)) { ErrorObserver = saved }(ErrorObserver)
+	var observed []string
+	ErrorObserver = func(status *errors.Status) {
+		observed = append(observed, status.Message)
+	}
+
+	secret := fmt.Errorf("dial postgres://user:secret@db: connection refused")
+	decode := func(w *httptest.ResponseRecorder) errors.Status {
+		var status errors.Status
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+		return status
+	}
+
+	RedactInternalErrors = false
+	w := httptest.NewRecorder()
+	WriteError(errors.NewInternalError(secret), w)
+	require.Contains(t, decode(w).Message, "secret")
+
+	RedactInternalErrors = true
+	for _, err := range []error{errors.NewInternalError(secret), secret} {
+		w = httptest.NewRecorder()
+		WriteError(err, w)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		status := decode(w)
+		require.Equal(t, "Internal error occurred", status.Message)
+		require.Nil(t, status.Details)
+		require.NotContains(t, w.Body.String(), "secret")
+	}
+	require.Len(t, observed, 3)
+	for _, message := range observed {
+		require.Contains(t, message, "secret")
+	}
+
+	// causes that trace the request are kept
+	w = httptest.NewRecorder()
+	WriteErrorWithRequestID(errors.NewInternalError(secret).WithDocumentationURL("https://example.com/docs"), w, "abc123")
+	status := decode(w)
+	require.Equal(t, "Internal error occurred", status.Message)
+	require.NotContains(t, w.Body.String(), "secret")
+	require.NotNil(t, status.Details)
+	require.Equal(t, []errors.StatusCause{
+		{Type: errors.CauseTypeDocumentationURL, Message: "see https://example.com/docs for more information", Value: "https://example.com/docs"},
+		{Type: errors.CauseTypeRequestID, Message: "request ID: abc123", Value: "abc123"},
+	}, status.Details.Causes)
+
+	w = httptest.NewRecorder()
+	WriteError(errors.NewNotFound("test", ""), w)
+	require.Equal(t, "test not found", decode(w).Message)
+}
+
+func TestErrorObserver(t *testing.T) {
+	var observed []*errors.Status
+	ErrorObserver = func(status *errors.Status) {
+		observed = append(observed, status)
+	}
+	defer func() { ErrorObserver = nil }()
+
+	WriteError(errors.NewNotFound("test", ""), httptest.NewRecorder())
+	require.Len(t, observed, 1)
+	require.Equal(t, errors.StatusReasonNotFound, observed[0].Reason)
+	require.Equal(t, int32(http.StatusNotFound), observed[0].Code)
+}
+
+func TestSecureWriteError(t *testing.T) {
+	w := httptest.NewRecorder()
+	SecureWriteError(errors.NewNotFound("test", ""), w)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	w = httptest.NewRecorder()
+	SecureWriteRawJSON(http.StatusOK, map[string]string{}, w)
+	require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	w = httptest.NewRecorder()
+	WriteError(errors.NewNotFound("test", ""), w)
+	require.Empty(t, w.Header().Get("X-Content-Type-Options"))
+}
+
+func TestWriteErrorWithRequestID(t *testing.T) {
+	original := errors.NewNotFound("test", "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteErrorWithRequestID(original, w, "abc-123")
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, "abc-123", resp.Header.Get("X-Request-Id"))
+
+	err, hasError := errors.FromResponse(resp)
+	require.True(t, hasError)
+	require.True(t, errors.IsNotFound(err))
+	cause, ok := errors.GetStatusCause(err, errors.CauseTypeRequestID)
+	require.True(t, ok)
+	require.Equal(t, "abc-123", cause.Value)
+	require.Empty(t, original.ErrStatus.Details.Causes)
+}
+
+func TestWriteErrorForRequest(t *testing.T) {
+	original := errors.NewNotFound("test", "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteErrorForRequest(original, w, r)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/v1/users?name=foo", "application/json", nil)
+	require.NoError(t, err)
+
+	err, hasError := errors.FromResponse(resp)
+	require.True(t, hasError)
+	require.True(t, errors.IsNotFound(err))
+	cause, ok := errors.GetStatusCause(err, errors.CauseTypeRequest)
+	require.True(t, ok)
+	require.Equal(t, "POST /api/v1/users", cause.Value)
+	require.Equal(t, "request: POST /api/v1/users", cause.Message)
+	require.Empty(t, original.ErrStatus.Details.Causes)
+}
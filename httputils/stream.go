@@ -0,0 +1,99 @@
+package httputils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// StreamErrorTrailer is the trailer in which a StreamingJSONWriter reports an
+// error that occurred after the response was started.
+const StreamErrorTrailer = "X-Stream-Error"
+
+// StreamingJSONWriter writes a JSON array incrementally, flushing after each
+// item, so that large lists can be streamed to the client. An error that
+// occurs once items have been written is reported in the StreamErrorTrailer
+// trailer as the compact JSON of its Status, since the status code has already
+// been sent.
+type StreamingJSONWriter struct {
+	w       http.ResponseWriter
+	started bool
+	done    bool
+}
+
+// NewStreamingJSONWriter returns a StreamingJSONWriter for w and declares the
+// StreamErrorTrailer trailer. It must be called before anything is written to
+// w.
+func NewStreamingJSONWriter(w http.ResponseWriter) *StreamingJSONWriter {
+	w.Header().Set("Trailer", StreamErrorTrailer)
+	return &StreamingJSONWriter{w: w}
+}
+
+// Write writes the item as the next element of the array. The response is
+// started with a 200 status code by the first call.
+func (s *StreamingJSONWriter) Write(item interface{}) error {
+	if s.done {
+		return fmt.Errorf("stream is already closed")
+	}
+	output, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	separator := ","
+	if !s.started {
+		s.start()
+		separator = ""
+	}
+	if _, err := s.w.Write([]byte(separator)); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(output); err != nil {
+		return err
+	}
+	flush(s.w)
+	return nil
+}
+
+// Close ends the array. An empty array is written if no items were written.
+func (s *StreamingJSONWriter) Close() error {
+	if s.done {
+		return nil
+	}
+	if !s.started {
+		s.start()
+	}
+	s.done = true
+	_, err := s.w.Write([]byte("]"))
+	return err
+}
+
+// Fail ends the stream with err. If no items have been written yet, err is
+// written as a regular error response with WriteError. Otherwise the array is
+// closed and the Status of err is reported in the StreamErrorTrailer trailer,
+// so clients must check the trailer after reading the body.
+func (s *StreamingJSONWriter) Fail(err error) {
+	if s.done {
+		return
+	}
+	if !s.started {
+		s.done = true
+		s.w.Header().Del("Trailer")
+		WriteError(err, s.w)
+		return
+	}
+	status := errorStatus(err, s.w)
+	s.Close()
+	output, marshalErr := json.Marshal(status)
+	if marshalErr != nil {
+		output = []byte(marshalErr.Error())
+	}
+	s.w.Header().Set(StreamErrorTrailer, string(output))
+}
+
+// start writes the header of the response and opens the array.
+func (s *StreamingJSONWriter) start() {
+	s.started = true
+	s.w.Header().Set("Content-Type", "application/json")
+	s.w.WriteHeader(http.StatusOK)
+	s.w.Write([]byte("["))
+}
@@ -0,0 +1,60 @@
+package httputils
+
+import (
+	"encoding/json"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamingJSONWriter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := NewStreamingJSONWriter(w)
+		require.NoError(t, s.Write(map[string]string{"name": "a"}))
+		require.NoError(t, s.Write(map[string]string{"name": "b"}))
+		s.Fail(errors.NewServiceUnavailable("backend went away"))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var items []map[string]string
+	require.NoError(t, json.Unmarshal(body, &items))
+	require.Equal(t, []map[string]string{{"name": "a"}, {"name": "b"}}, items)
+
+	// the trailer is only available once the body has been read
+	status, err := errors.ParseStatusJSON([]byte(resp.Trailer.Get(StreamErrorTrailer)))
+	require.NoError(t, err)
+	require.Equal(t, errors.StatusReasonServiceUnavailable, status.Reason)
+	require.Equal(t, "backend went away", status.Message)
+}
+
+func TestStreamingJSONWriterClose(t *testing.T) {
+	w := httptest.NewRecorder()
+	s := NewStreamingJSONWriter(w)
+	require.NoError(t, s.Close())
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "[]", w.Body.String())
+	require.Error(t, s.Write("late"))
+	require.Empty(t, w.Header().Get(StreamErrorTrailer))
+}
+
+func TestStreamingJSONWriterFailBeforeStart(t *testing.T) {
+	w := httptest.NewRecorder()
+	s := NewStreamingJSONWriter(w)
+	s.Fail(errors.NewNotFound("items", ""))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Empty(t, w.Header().Get("Trailer"))
+
+	status, err := errors.ParseStatusJSON(w.Body.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, errors.StatusReasonNotFound, status.Reason)
+}
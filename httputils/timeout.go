@@ -0,0 +1,107 @@
+package httputils
+
+import (
+	"bytes"
+	"context"
+	"github.com/clarkmcc/apiutils/errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeout is a middleware that limits the time the next handler may take to d,
+// like http.TimeoutHandler. If the handler does not finish in time, the client
+// receives a server timeout error for the operation, see
+// errors.NewServerTimeout, with a Retry-After header of d rounded up to whole
+// seconds. The context of the request is canceled at the deadline, and writes
+// by the handler after the deadline fail with http.ErrHandlerTimeout. As with
+// http.TimeoutHandler, the response of the handler is buffered, so the handler
+// cannot flush partial responses.
+func Timeout(d time.Duration, operation string) func(http.Handler) http.Handler {
+	retryAfterSeconds := int(math.Ceil(d.Seconds()))
+	if retryAfterSeconds < 1 {
+		retryAfterSeconds = 1
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{header: make(http.Header)}
+			done := make(chan struct{})
+			panics := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panics <- p
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case p := <-panics:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				for k, v := range tw.header {
+					w.Header()[k] = v
+				}
+				if tw.code == 0 {
+					tw.code = http.StatusOK
+				}
+				w.WriteHeader(tw.code)
+				w.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				if ctx.Err() == context.DeadlineExceeded {
+					WriteError(errors.NewServerTimeout(operation, retryAfterSeconds), w)
+				} else {
+					WriteError(errors.FromStandardError(ctx.Err()), w)
+				}
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers the response of the handler wrapped by Timeout until
+// the handler finishes or times out.
+type timeoutWriter struct {
+	header http.Header
+
+	mu       sync.Mutex
+	body     bytes.Buffer
+	code     int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.body.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
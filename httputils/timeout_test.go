@@ -0,0 +1,59 @@
+package httputils
+
+import (
+	"encoding/json"
+	"github.com/clarkmcc/apiutils/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	writeErr := make(chan error, 1)
+	handler := Timeout(10*time.Millisecond, "sync")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, err := w.Write([]byte("too late"))
+		writeErr <- err
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	var status errors.Status
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, errors.StatusReasonServerTimeout, status.Reason)
+	require.Equal(t, "sync", status.Details.Name)
+	require.Equal(t, int32(1), status.Details.RetryAfterSeconds)
+
+	close(release)
+	require.Equal(t, http.ErrHandlerTimeout, <-writeErr)
+	require.NotContains(t, w.Body.String(), "too late")
+}
+
+func TestTimeoutFastHandler(t *testing.T) {
+	handler := Timeout(time.Second, "sync")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "value")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "value", w.Header().Get("X-Test"))
+	require.Equal(t, "created", w.Body.String())
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	handler := Timeout(time.Second, "sync")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	}))
+	require.PanicsWithValue(t, "something went wrong", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}